@@ -0,0 +1,355 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"wasm-bugs/src/world"
+)
+
+func main() {
+	width := flag.Int("width", 600, "width of the world")
+	height := flag.Int("height", 600, "height of the world")
+	cycles := flag.Int("cycles", 100000, "number of cycles to run, 0 to run until all bugs are dead")
+	every := flag.Int("every", 1000, "print a history summary every N cycles")
+	initialBacteria := flag.Int("bacteria", 3, "starting bacteria as a percentage of the world (0-100)")
+	initialBugs := flag.Int("bugs", 20, "number of bugs to start with")
+	initialPredators := flag.Int("predators", 0, "number of predators hunting the bugs to start with")
+	reseedRate := flag.Int("reseed", 50, "bacteria reseed rate (1-300)")
+	growth := world.DefaultGrowth()
+	growthName := flag.String("growth", growth.Model.String(), "how bacteria regrow: random, or spread into neighbouring cells")
+	flag.IntVar(&growth.SpreadRate, "spread-rate", growth.SpreadRate, "with -growth spread, chance in 1000 of each bacterium spreading every cycle")
+	flag.IntVar(&growth.CarryingCapacity, "capacity", growth.CarryingCapacity, "with -growth spread, percentage of the world food of every kind can cover")
+	geometryName := flag.String("geometry", "hex", "directions bugs can move in: "+strings.Join(world.GeometryNames(), ", "))
+	directions := flag.String("directions", "", "custom directions bugs can move in, as X,Y offsets in turning order, e.g. \"0,2 2,0 0,-2 -2,0\"; overrides -geometry")
+	classifierName := flag.String("classifier", "", "how bugs are classified: "+strings.Join(world.ClassifierNames(), ", ")+" (default forward, or the one stored in a loaded snapshot)")
+	seed := flag.Uint64("seed", 0, "random number seed, 0 picks a random seed")
+	loadFile := flag.String("load", "", "resume from a snapshot file instead of starting a new world, using the parameters stored in it")
+	saveFile := flag.String("save", "", "write a snapshot of the world to this file when the run ends")
+	historyFile := flag.String("history", "", "write the population history to this file when the run ends, as JSON if it ends in .json, otherwise CSV")
+	lineageFile := flag.String("lineage", "", "record the lineage of every bug and write it to this file when the run ends, as JSON if it ends in .json, otherwise Newick")
+	sexual := flag.Bool("sexual", false, "bugs ready to split wait for a ready mate and their children get a crossover of both genomes")
+	fullHistory := flag.Bool("full-history", false, "keep every history entry instead of only the last width entries")
+	regions := []*world.FertilityRegion{}
+	flag.Func("region", "add a fertility region, as \"rect X Y WIDTH HEIGHT RATE\" or \"circle X Y RADIUS RATE\" (repeatable)", func(s string) error {
+		region, err := world.ParseFertilityRegion(s)
+		if err != nil {
+			return err
+		}
+		regions = append(regions, region)
+		return nil
+	})
+	foods := []*world.FoodType{}
+	flag.Func("food", "add a kind of food besides the bacteria, as \"NAME COLOR ENERGY START RATE\", negative energy for toxic food (repeatable)", func(s string) error {
+		food, err := world.ParseFoodType(s)
+		if err != nil {
+			return err
+		}
+		foods = append(foods, food)
+		return world.ValidateFoodTypes(foods)
+	})
+	foodPreferences := flag.Bool("food-preferences", false, "bugs evolve a preferred food and get half the energy from any other food")
+	sensing := flag.Bool("sensing", false, "bugs sense the food ahead, to their left and to their right, and evolve genes weighing it into their turns")
+	networkBrains := flag.Int("network-brains", 0, "percentage of the starting bugs steered by a neural network instead of their turn genes")
+	schedules := []*world.Schedule{}
+	flag.Func("schedule", "change "+strings.Join(world.ScheduleParams(), ", ")+" over time, as \"PARAM sine MEAN AMPLITUDE PERIOD [PHASE]\", \"PARAM step CYCLE:VALUE ...\" or \"PARAM table CYCLE:VALUE ...\", optionally ending in \"repeat CYCLES\" (repeatable)", func(s string) error {
+		schedule, err := world.ParseSchedule(s)
+		if err != nil {
+			return err
+		}
+		schedules = append(schedules, schedule)
+		return nil
+	})
+	fertilityMapFile := flag.String("fertility-map", "", "grayscale PNG setting how readily bacteria regrow across the world")
+	terrainFile := flag.String("terrain", "", "PNG whose dark areas are walls bugs cannot cross and bacteria cannot grow on")
+	boundaryName := flag.String("boundary", "torus", "what happens to bugs at the edges of the world: torus, reflect or absorb")
+	rules := world.DefaultRules()
+	flag.IntVar(&rules.ReproduceAge, "reproduce-age", rules.ReproduceAge, "a bug must be older than this to split")
+	flag.IntVar(&rules.ReproduceEnergy, "reproduce-energy", rules.ReproduceEnergy, "a bug must have more energy than this to split")
+	flag.IntVar(&rules.MaxEnergy, "max-energy", rules.MaxEnergy, "most energy a bug can store")
+	flag.IntVar(&rules.StartingEnergy, "starting-energy", rules.StartingEnergy, "energy of the starting bugs")
+	flag.IntVar(&rules.EnergyPerBacterium, "bacterium-energy", rules.EnergyPerBacterium, "energy gained for each bacterium eaten")
+	flag.IntVar(&rules.FeedingRadius, "feeding-radius", rules.FeedingRadius, "cells around a bug it eats from, 1 is a 3x3 area")
+	flag.IntVar(&rules.MatingRadius, "mating-radius", rules.MatingRadius, "how close two bugs must be to mate with -sexual, 2 is a 5x5 area")
+	flag.IntVar(&rules.HistoryInterval, "history-interval", rules.HistoryInterval, "cycles between history samples")
+	flag.IntVar(&rules.Predator.ReproduceAge, "predator-reproduce-age", rules.Predator.ReproduceAge, "a predator must be older than this to split")
+	flag.IntVar(&rules.Predator.ReproduceEnergy, "predator-reproduce-energy", rules.Predator.ReproduceEnergy, "a predator must have more energy than this to split")
+	flag.IntVar(&rules.Predator.MaxEnergy, "predator-max-energy", rules.Predator.MaxEnergy, "most energy a predator can store")
+	flag.IntVar(&rules.Predator.StartingEnergy, "predator-starting-energy", rules.Predator.StartingEnergy, "energy of the starting predators")
+	flag.IntVar(&rules.Predator.EnergyPerBug, "bug-energy", rules.Predator.EnergyPerBug, "energy a predator gains for each bug eaten")
+	flag.IntVar(&rules.Predator.FeedingRadius, "predator-feeding-radius", rules.Predator.FeedingRadius, "cells around a predator it catches bugs in, 2 is a 5x5 area")
+	competition := world.Competition{}
+	feedingOrder := flag.String("feeding-order", competition.FeedingOrder.String(), "which bugs move and feed first: slice (stored order), random, or energy (most energy first)")
+	flag.BoolVar(&competition.Exclusion, "exclusion", false, "a bug cannot move onto a cell another bug is on")
+	flag.IntVar(&competition.StealPercent, "steal", 0, "bugs sharing a cell fight and the winner takes this percentage of each loser's energy, 0 for no fights")
+	mutation := world.MutationPolicy{}
+	flag.BoolVar(&mutation.Off, "no-mutation", false, "children are exact copies of their parent, for control runs")
+	flag.Float64Var(&mutation.GeneRate, "mutation-rate", 0, "chance of each gene mutating, 0 mutates exactly one gene")
+	flag.Float64Var(&mutation.StepSize, "mutation-step", 0, "standard deviation of Gaussian mutation steps, 0 steps by exactly one")
+	flag.IntVar(&mutation.MinGene, "gene-min", 0, "lowest value a gene can start at or mutate to, unbounded if it and -gene-max are 0")
+	flag.IntVar(&mutation.MaxGene, "gene-max", 0, "highest value a gene can start at or mutate to, unbounded if it and -gene-min are 0")
+	flag.Parse()
+
+	if *initialPredators < 0 {
+		fmt.Fprintln(os.Stderr, "predators must not be negative")
+		os.Exit(2)
+	}
+	if *networkBrains < 0 || *networkBrains > 100 {
+		fmt.Fprintln(os.Stderr, "network-brains must be between 0 and 100")
+		os.Exit(2)
+	}
+	if *width <= 0 || *height <= 0 {
+		fmt.Fprintln(os.Stderr, "width and height must be positive")
+		os.Exit(2)
+	}
+	if *every <= 0 {
+		fmt.Fprintln(os.Stderr, "every must be positive")
+		os.Exit(2)
+	}
+	if err := rules.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	order, err := world.ParseFeedingOrder(*feedingOrder)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	competition.FeedingOrder = order
+	if err := competition.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if err := mutation.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	geometry, err := world.GeometryByName(*geometryName)
+	if *directions != "" {
+		geometry, err = world.ParseDirections(*directions)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	boundary, err := world.ParseBoundary(*boundaryName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	growth.Model, err = world.ParseGrowthModel(*growthName)
+	if err == nil {
+		err = growth.Validate()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	var classifier world.Classifier
+	if *classifierName != "" {
+		classifier, err = world.ClassifierByName(*classifierName)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+
+	gameWorld := world.NewGameWorld(*width, *height)
+	gameWorld.InitialBacteria = *initialBacteria
+	gameWorld.InitialBugCount = *initialBugs
+	gameWorld.InitialPredatorCount = *initialPredators
+	gameWorld.ReseedBacteria = *reseedRate
+	gameWorld.Seed = *seed
+	gameWorld.KeepFullHistory = *fullHistory
+	gameWorld.RecordLineage = *lineageFile != ""
+	gameWorld.SexualReproduction = *sexual
+	gameWorld.Rules = rules
+	gameWorld.Mutation = mutation
+	gameWorld.Competition = competition
+	gameWorld.Geometry = geometry
+	gameWorld.FertilityRegions = regions
+	gameWorld.Growth = growth
+	gameWorld.Schedules = schedules
+	gameWorld.FoodTypes = foods
+	gameWorld.FoodPreferences = *foodPreferences
+	gameWorld.Sensing = *sensing
+	gameWorld.NetworkBrains = *networkBrains
+	gameWorld.Boundary = boundary
+	if *fertilityMapFile != "" {
+		m, err := loadFertilityMap(*fertilityMapFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "unable to load fertility map %s: %v\n", *fertilityMapFile, err)
+			os.Exit(1)
+		}
+		gameWorld.FertilityMap = m
+	}
+	if *terrainFile != "" {
+		t, err := loadTerrain(*terrainFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "unable to load terrain %s: %v\n", *terrainFile, err)
+			os.Exit(1)
+		}
+		gameWorld.Terrain = t
+	}
+	if *loadFile != "" {
+		data, err := os.ReadFile(*loadFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if err := gameWorld.UnmarshalSnapshot(data); err != nil {
+			fmt.Fprintf(os.Stderr, "unable to load %s: %v\n", *loadFile, err)
+			os.Exit(1)
+		}
+		if *lineageFile != "" {
+			gameWorld.RecordLineage = true
+		}
+	} else {
+		gameWorld.Initialize()
+	}
+	if classifier != nil {
+		gameWorld.SetClassifier(classifier)
+	}
+
+	fmt.Printf("seed %d\n", gameWorld.Seed)
+	fmt.Printf("mutation %s\n", gameWorld.Mutation)
+
+	printHeader(gameWorld)
+	for *cycles == 0 || gameWorld.Cycle() < *cycles {
+		err := gameWorld.Next()
+		if gameWorld.Cycle()%*every == 0 {
+			printSummary(gameWorld)
+		}
+		if err == world.NoBugsError {
+			fmt.Printf("all bugs died at cycle %d\n", gameWorld.Cycle())
+			break
+		} else if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	if *saveFile != "" {
+		data, err := gameWorld.MarshalSnapshot()
+		if err == nil {
+			err = os.WriteFile(*saveFile, data, 0644)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "unable to save %s: %v\n", *saveFile, err)
+			os.Exit(1)
+		}
+	}
+
+	if *historyFile != "" {
+		if err := writeHistory(*historyFile, gameWorld.History()); err != nil {
+			fmt.Fprintf(os.Stderr, "unable to write history %s: %v\n", *historyFile, err)
+			os.Exit(1)
+		}
+	}
+
+	if *lineageFile != "" {
+		if err := writeLineage(*lineageFile, gameWorld); err != nil {
+			fmt.Fprintf(os.Stderr, "unable to write lineage %s: %v\n", *lineageFile, err)
+			os.Exit(1)
+		}
+	}
+}
+
+func writeLineage(filename string, w *world.GameWorld) error {
+	lineage := w.Lineage()
+	if lineage == nil {
+		return fmt.Errorf("lineage was not recorded")
+	}
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if strings.HasSuffix(strings.ToLower(filename), ".json") {
+		err = lineage.WriteJSON(f)
+	} else {
+		err = lineage.WriteNewick(f, w.Cycle())
+	}
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+func loadFertilityMap(filename string) (*world.FertilityMap, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return world.LoadFertilityMap(f)
+}
+
+func loadTerrain(filename string) (*world.Terrain, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	t, err := world.LoadTerrain(f)
+	if err != nil {
+		return nil, err
+	}
+	return t, t.Validate()
+}
+
+func writeHistory(filename string, history []world.HistoryEntry) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if strings.HasSuffix(strings.ToLower(filename), ".json") {
+		err = world.WriteHistoryJSON(f, history)
+	} else {
+		err = world.WriteHistoryCSV(f, history)
+	}
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+func printHeader(w *world.GameWorld) {
+	fmt.Printf("%10s %10s %8s %8s %9s", "cycle", "bacteria", "percent", "bugs", "predators")
+	if len(w.FoodTypes) > 0 {
+		for _, name := range w.FoodNames() {
+			fmt.Printf(" %10s", strings.ToLower(name))
+		}
+	}
+	for _, class := range w.Classifier().Classes() {
+		fmt.Printf(" %10s", strings.ToLower(class.Name))
+	}
+	fmt.Println()
+}
+
+// printSummary prints a row for the world as it stands, whether or not the
+// history sampled this cycle.
+func printSummary(w *world.GameWorld) {
+	h := w.Summary()
+	fmt.Printf("%10d %10d %7.2f%% %8d %9d",
+		h.Cycle, h.BacteriaCount, h.BacteriaPercent*100, h.BugCount, h.PredatorCount)
+	if len(w.FoodTypes) > 0 {
+		for _, name := range w.FoodNames() {
+			fmt.Printf(" %10d", h.Food[name])
+		}
+	}
+	for _, class := range w.Classifier().Classes() {
+		fmt.Printf(" %10d", h.Classes[class.Name])
+	}
+	fmt.Println()
+}
@@ -0,0 +1,50 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"syscall/js"
+)
+
+// downloadFile hands data to the browser as a file download.
+func downloadFile(name, mimeType string, data []byte) {
+	array := js.Global().Get("Uint8Array").New(len(data))
+	js.CopyBytesToJS(array, data)
+
+	blob := js.Global().Get("Blob").New([]interface{}{array}, map[string]interface{}{"type": mimeType})
+	url := js.Global().Get("URL").Call("createObjectURL", blob)
+
+	link := js.Global().Get("document").Call("createElement", "a")
+	link.Set("href", url)
+	link.Set("download", name)
+	link.Call("click")
+
+	js.Global().Get("URL").Call("revokeObjectURL", url)
+}
+
+// readFile reads the first file selected in a file input and passes its
+// contents to onLoad once the browser has finished reading it.
+func readFile(input js.Value, onLoad func(data []byte)) {
+	files := input.Get("files")
+	if files.Length() == 0 {
+		return
+	}
+
+	var then js.Func
+	then = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		defer then.Release()
+
+		array := js.Global().Get("Uint8Array").New(args[0])
+		data := make([]byte, array.Get("length").Int())
+		js.CopyBytesToGo(data, array)
+
+		// Clear the input so selecting the same file again fires another change event
+		input.Set("value", "")
+
+		onLoad(data)
+		return nil
+	})
+
+	files.Index(0).Call("arrayBuffer").Call("then", then)
+}
@@ -0,0 +1,118 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"syscall/js"
+
+	"wasm-bugs/src/world"
+)
+
+var (
+	bugDetails     js.Value
+	unfollowButton js.Value
+	selectedBug    *world.Bug
+)
+
+// canvasPosition returns the world cell under a mouse event on the game
+// canvas.
+func canvasPosition(event js.Value) (int, int) {
+	rect := canvas.Call("getBoundingClientRect")
+	scaleX := canvas.Get("width").Float() / rect.Get("width").Float()
+	scaleY := canvas.Get("height").Float() / rect.Get("height").Float()
+	x := int((event.Get("clientX").Float() - rect.Get("left").Float()) * scaleX)
+	y := int((event.Get("clientY").Float() - rect.Get("top").Float()) * scaleY)
+	return x, y
+}
+
+// selectBug picks the bug nearest to a click on the game canvas.
+func selectBug(this js.Value, args []js.Value) interface{} {
+	if drawWalls.Get("checked").Bool() {
+		return nil
+	}
+
+	x, y := canvasPosition(args[0])
+	if x < 0 || y < 0 || x >= gameWorld.Width || y >= gameWorld.Height {
+		return nil
+	}
+
+	selectedBug = gameWorld.NearestBug(x, y)
+	gameWorld.Follow(selectedBug)
+	if !started {
+		draw()
+	}
+
+	return nil
+}
+
+func unfollowBug(this js.Value, args []js.Value) interface{} {
+	selectedBug = nil
+	gameWorld.Follow(nil)
+	if !started {
+		draw()
+	}
+
+	return nil
+}
+
+func showBugDetails() {
+	if selectedBug == nil {
+		bugDetails.Set("innerHTML", `<div class="form-text">Click on the world to inspect a bug and follow its descendants</div>`)
+		unfollowButton.Set("hidden", true)
+		return
+	}
+	unfollowButton.Set("hidden", false)
+
+	b := selectedBug
+	var sb strings.Builder
+
+	if !gameWorld.IsAlive(b) {
+		sb.WriteString(`<div class="form-text mb-2">This bug is no longer alive</div>`)
+	}
+
+	sb.WriteString(`<table class="table table-sm table-dark mb-2">`)
+	fmt.Fprintf(&sb, "<tr><td>ID</td><td>%d</td></tr>", b.ID)
+	if b.ParentID != 0 {
+		fmt.Fprintf(&sb, "<tr><td>Parent</td><td>%d</td></tr>", b.ParentID)
+	}
+	fmt.Fprintf(&sb, "<tr><td>Position</td><td>%d, %d</td></tr>", b.X, b.Y)
+	fmt.Fprintf(&sb, "<tr><td>Age</td><td>%d</td></tr>", b.Age)
+	fmt.Fprintf(&sb, "<tr><td>Energy</td><td>%d</td></tr>", b.Energy)
+	fmt.Fprintf(&sb, "<tr><td>Class</td><td>%s</td></tr>", b.Classification)
+	if names := gameWorld.FoodNames(); gameWorld.FoodPreferences && b.FoodPreference < len(names) {
+		fmt.Fprintf(&sb, "<tr><td>Prefers</td><td>%s</td></tr>", names[b.FoodPreference])
+	}
+	fmt.Fprintf(&sb, "<tr><td>Direction</td><td>%d</td></tr>", b.Direction())
+	fmt.Fprintf(&sb, "<tr><td>Brain</td><td>%s</td></tr>", b.Brain().Name())
+	sb.WriteString("</table>")
+
+	values := b.GeneValues()
+	weights := b.GeneWeights()
+	probabilities := b.TurnProbabilities()
+	sb.WriteString(`<table class="table table-sm table-dark">`)
+	sb.WriteString("<tr><th>Turn</th><th>Gene</th><th>Weight</th><th>Chance</th></tr>")
+	for i := range values {
+		fmt.Fprintf(&sb, "<tr><td>%d</td><td>%d</td><td>%d</td><td>%.1f%%</td></tr>",
+			i, values[i], weights[i], probabilities[i]*100)
+	}
+	sb.WriteString("</table>")
+
+	if senses := b.SenseGenes(); senses != nil {
+		sensed := b.Sensed()
+		sb.WriteString(`<table class="table table-sm table-dark">`)
+		sb.WriteString("<tr><th>Sense</th><th>Gene</th><th>Food</th></tr>")
+		for i, name := range []string{"Ahead", "Left", "Right"} {
+			food := 0
+			if i < len(sensed) {
+				food = sensed[i]
+			}
+			fmt.Fprintf(&sb, "<tr><td>%s</td><td>%d</td><td>%d</td></tr>", name, senses[i], food)
+		}
+		sb.WriteString("</table>")
+	}
+
+	bugDetails.Set("innerHTML", sb.String())
+}
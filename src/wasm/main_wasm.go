@@ -4,7 +4,10 @@
 package main
 
 import (
+	"bytes"
+	"fmt"
 	"strconv"
+	"strings"
 	"syscall/js"
 	"time"
 
@@ -23,14 +26,43 @@ var (
 	ctx              js.Value
 	reportCanvas     js.Value
 	reportCtx        js.Value
+	genomeCanvas     js.Value
+	genomeCtx        js.Value
 	startButton      js.Value
 	pauseButton      js.Value
 	resetButton      js.Value
+	applyButton      js.Value
+	saveButton       js.Value
+	loadButton       js.Value
+	loadInput        js.Value
 	startingBacteria js.Value
 	startingBugs     js.Value
+	startingPreds    js.Value
 	reseedRate       js.Value
+	growthInput      js.Value
+	spreadRate       js.Value
+	carryingCapacity js.Value
+	fertilityRegions js.Value
+	schedules        js.Value
+	foodTypes        js.Value
+	foodPreferences  js.Value
+	sensingInput     js.Value
+	networkBrains    js.Value
+	fertilityMap     js.Value
+	clearMapButton   js.Value
+	seedInput        js.Value
+	geometryInput    js.Value
+	directionsInput  js.Value
+	classifierInput  js.Value
 	reportViewButton js.Value
 	gameViewButton   js.Value
+	exportCSVButton  js.Value
+	exportJSONButton js.Value
+	fullHistory      js.Value
+	exportNewick     js.Value
+	exportLineage    js.Value
+	recordLineage    js.Value
+	sexualRepro      js.Value
 	reportView       js.Value
 	gameView         js.Value
 
@@ -39,6 +71,7 @@ var (
 	screenView world.ScreenView
 
 	gameWorld *world.GameWorld
+	renderer  *CanvasRenderer
 )
 
 func main() {
@@ -59,6 +92,24 @@ func main() {
 	reportCanvas.Set("width", window.Get("innerWidth").Int())
 	reportCanvas.Set("height", window.Get("innerHeight").Int())
 
+	genomeCanvas = doc.Call("getElementById", "genomeCanvas")
+	genomeCtx = genomeCanvas.Call("getContext", "2d")
+
+	canvas.Call("addEventListener", "click", js.FuncOf(selectBug))
+
+	bugDetails = doc.Call("getElementById", "bug-details")
+	if bugDetails.IsNull() {
+		println("Failed to get bug-details")
+		return
+	}
+
+	unfollowButton = doc.Call("getElementById", "unfollow-btn")
+	if unfollowButton.IsNull() {
+		println("Failed to get unfollow-btn")
+		return
+	}
+	unfollowButton.Call("addEventListener", "click", js.FuncOf(unfollowBug))
+
 	// Add event listener to the start button
 	startButton = doc.Call("getElementById", "startButton")
 	if startButton.IsNull() {
@@ -81,6 +132,37 @@ func main() {
 	}
 	resetButton.Call("addEventListener", "click", js.FuncOf(resetGame))
 
+	applyButton = doc.Call("getElementById", "applyButton")
+	if applyButton.IsNull() {
+		println("Failed to get apply button")
+		return
+	}
+	applyButton.Call("addEventListener", "click", js.FuncOf(applySettings))
+
+	saveButton = doc.Call("getElementById", "saveButton")
+	if saveButton.IsNull() {
+		println("Failed to get save button")
+		return
+	}
+	saveButton.Call("addEventListener", "click", js.FuncOf(saveGame))
+
+	loadInput = doc.Call("getElementById", "load-input")
+	if loadInput.IsNull() {
+		println("Failed to get load input")
+		return
+	}
+	loadInput.Call("addEventListener", "change", js.FuncOf(loadGame))
+
+	loadButton = doc.Call("getElementById", "loadButton")
+	if loadButton.IsNull() {
+		println("Failed to get load button")
+		return
+	}
+	loadButton.Call("addEventListener", "click", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		loadInput.Call("click")
+		return nil
+	}))
+
 	startingBacteria = doc.Call("getElementById", "starting_bacteria")
 	if startingBacteria.IsNull() {
 		println("Failed to get starting bacteria")
@@ -91,11 +173,113 @@ func main() {
 		println("Failed to get starting bugs")
 		return
 	}
+	startingPreds = doc.Call("getElementById", "starting_predators")
+	if startingPreds.IsNull() {
+		println("Failed to get starting predators")
+		return
+	}
 	reseedRate = doc.Call("getElementById", "reseed_rate")
 	if reseedRate.IsNull() {
 		println("Failed to get reseed rate")
 		return
 	}
+	growthInput = doc.Call("getElementById", "growth")
+	if growthInput.IsNull() {
+		println("Failed to get growth")
+		return
+	}
+	spreadRate = doc.Call("getElementById", "spread_rate")
+	if spreadRate.IsNull() {
+		println("Failed to get spread rate")
+		return
+	}
+	carryingCapacity = doc.Call("getElementById", "carrying_capacity")
+	if carryingCapacity.IsNull() {
+		println("Failed to get carrying capacity")
+		return
+	}
+	schedules = doc.Call("getElementById", "schedules")
+	if schedules.IsNull() {
+		println("Failed to get schedules")
+		return
+	}
+	fertilityRegions = doc.Call("getElementById", "fertility_regions")
+	if fertilityRegions.IsNull() {
+		println("Failed to get fertility regions")
+		return
+	}
+	foodTypes = doc.Call("getElementById", "food_types")
+	if foodTypes.IsNull() {
+		println("Failed to get food types")
+		return
+	}
+	foodPreferences = doc.Call("getElementById", "food_preferences")
+	if foodPreferences.IsNull() {
+		println("Failed to get food preferences")
+		return
+	}
+	sensingInput = doc.Call("getElementById", "sensing")
+	if sensingInput.IsNull() {
+		println("Failed to get sensing")
+		return
+	}
+	networkBrains = doc.Call("getElementById", "network_brains")
+	if networkBrains.IsNull() {
+		println("Failed to get network brains")
+		return
+	}
+	fertilityMap = doc.Call("getElementById", "fertility_map")
+	if fertilityMap.IsNull() {
+		println("Failed to get fertility map")
+		return
+	}
+	fertilityMap.Call("addEventListener", "change", js.FuncOf(loadFertilityMap))
+
+	clearMapButton = doc.Call("getElementById", "clear-fertility-map-btn")
+	if clearMapButton.IsNull() {
+		println("Failed to get clear-fertility-map-btn")
+		return
+	}
+	clearMapButton.Call("addEventListener", "click", js.FuncOf(clearFertilityMap))
+
+	if !findRuleInputs(doc) {
+		return
+	}
+	showRules()
+
+	if !findTerrainInputs(doc) {
+		return
+	}
+	showTerrain()
+
+	sexualRepro = doc.Call("getElementById", "sexual_reproduction")
+	if sexualRepro.IsNull() {
+		println("Failed to get sexual_reproduction")
+		return
+	}
+
+	geometryInput = doc.Call("getElementById", "geometry")
+	if geometryInput.IsNull() {
+		println("Failed to get geometry")
+		return
+	}
+	directionsInput = doc.Call("getElementById", "directions")
+	if directionsInput.IsNull() {
+		println("Failed to get directions")
+		return
+	}
+	classifierInput = doc.Call("getElementById", "classifier")
+	if classifierInput.IsNull() {
+		println("Failed to get classifier")
+		return
+	}
+	classifierInput.Call("addEventListener", "change", js.FuncOf(setClassifier))
+
+	seedInput = doc.Call("getElementById", "seed")
+	if seedInput.IsNull() {
+		println("Failed to get seed")
+		return
+	}
 	gameViewButton = doc.Call("getElementById", "game-view-btn")
 	if gameViewButton.IsNull() {
 		println("Failed to get game-view-btn")
@@ -122,8 +306,60 @@ func main() {
 		return
 	}
 
-	gameWorld.Initialize(canvas, ctx, reportCanvas, reportCtx)
-	gameWorld.DrawBackground(canvas, ctx)
+	exportCSVButton = doc.Call("getElementById", "export-csv-btn")
+	if exportCSVButton.IsNull() {
+		println("Failed to get export-csv-btn")
+		return
+	}
+	exportCSVButton.Call("addEventListener", "click", js.FuncOf(exportCSV))
+
+	exportJSONButton = doc.Call("getElementById", "export-json-btn")
+	if exportJSONButton.IsNull() {
+		println("Failed to get export-json-btn")
+		return
+	}
+	exportJSONButton.Call("addEventListener", "click", js.FuncOf(exportJSON))
+
+	fullHistory = doc.Call("getElementById", "full_history")
+	if fullHistory.IsNull() {
+		println("Failed to get full_history")
+		return
+	}
+	fullHistory.Call("addEventListener", "change", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		gameWorld.KeepFullHistory = fullHistory.Get("checked").Bool()
+		return nil
+	}))
+
+	exportNewick = doc.Call("getElementById", "export-newick-btn")
+	if exportNewick.IsNull() {
+		println("Failed to get export-newick-btn")
+		return
+	}
+	exportNewick.Call("addEventListener", "click", js.FuncOf(exportLineageNewick))
+
+	exportLineage = doc.Call("getElementById", "export-lineage-btn")
+	if exportLineage.IsNull() {
+		println("Failed to get export-lineage-btn")
+		return
+	}
+	exportLineage.Call("addEventListener", "click", js.FuncOf(exportLineageJSON))
+
+	recordLineage = doc.Call("getElementById", "record_lineage")
+	if recordLineage.IsNull() {
+		println("Failed to get record_lineage")
+		return
+	}
+	recordLineage.Call("addEventListener", "change", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		gameWorld.RecordLineage = recordLineage.Get("checked").Bool()
+		return nil
+	}))
+
+	renderer = NewCanvasRenderer(canvas, ctx, reportCanvas, reportCtx, genomeCanvas, genomeCtx, WORLD_HEIGHT)
+	gameWorld.SetRenderer(renderer)
+	setParams()
+	gameWorld.Initialize()
+	renderer.DrawBackground(world.GAME_VIEW, gameWorld)
+	showBugDetails()
 	paused = false
 	screenView = world.GAME_VIEW
 
@@ -134,13 +370,53 @@ func main() {
 func enableInputs() {
 	startingBacteria.Set("disabled", false)
 	startingBugs.Set("disabled", false)
+	startingPreds.Set("disabled", false)
 	reseedRate.Set("disabled", false)
+	growthInput.Set("disabled", false)
+	spreadRate.Set("disabled", false)
+	carryingCapacity.Set("disabled", false)
+	seedInput.Set("disabled", false)
+	geometryInput.Set("disabled", false)
+	directionsInput.Set("disabled", false)
+	fertilityRegions.Set("disabled", false)
+	schedules.Set("disabled", false)
+	foodTypes.Set("disabled", false)
+	foodPreferences.Set("disabled", false)
+	sensingInput.Set("disabled", false)
+	networkBrains.Set("disabled", false)
+	fertilityMap.Set("disabled", false)
+	clearMapButton.Set("disabled", false)
+	loadButton.Set("disabled", false)
+	applyButton.Set("disabled", false)
+	sexualRepro.Set("disabled", false)
+	setRuleInputsDisabled(false)
+	setTerrainInputsDisabled(false)
 }
 
 func disableInputs() {
 	startingBacteria.Set("disabled", true)
 	startingBugs.Set("disabled", true)
+	startingPreds.Set("disabled", true)
 	reseedRate.Set("disabled", true)
+	growthInput.Set("disabled", true)
+	spreadRate.Set("disabled", true)
+	carryingCapacity.Set("disabled", true)
+	seedInput.Set("disabled", true)
+	geometryInput.Set("disabled", true)
+	directionsInput.Set("disabled", true)
+	fertilityRegions.Set("disabled", true)
+	schedules.Set("disabled", true)
+	foodTypes.Set("disabled", true)
+	foodPreferences.Set("disabled", true)
+	sensingInput.Set("disabled", true)
+	networkBrains.Set("disabled", true)
+	fertilityMap.Set("disabled", true)
+	clearMapButton.Set("disabled", true)
+	loadButton.Set("disabled", true)
+	applyButton.Set("disabled", true)
+	sexualRepro.Set("disabled", true)
+	setRuleInputsDisabled(true)
+	setTerrainInputsDisabled(true)
 }
 
 func setParams() {
@@ -160,6 +436,14 @@ func setParams() {
 		gameWorld.InitialBugCount = n
 	}
 
+	v = startingPreds.Get("value").String()
+	n, err = strconv.Atoi(v)
+	if err != nil || n < 0 {
+		println("Invalid number for starting predators")
+	} else {
+		gameWorld.InitialPredatorCount = n
+	}
+
 	v = reseedRate.Get("value").String()
 	n, err = strconv.Atoi(v)
 	if err != nil {
@@ -167,11 +451,284 @@ func setParams() {
 	} else {
 		gameWorld.ReseedBacteria = n
 	}
+
+	setGrowth()
+
+	regions, err := world.ParseFertilityRegions(fertilityRegions.Get("value").String())
+	if err != nil {
+		println("Invalid fertility regions: " + err.Error())
+	} else {
+		gameWorld.FertilityRegions = regions
+	}
+
+	scheduleList, err := world.ParseSchedules(schedules.Get("value").String())
+	if err != nil {
+		println("Invalid schedules: " + err.Error())
+	} else {
+		gameWorld.Schedules = scheduleList
+	}
+
+	foods, err := world.ParseFoodTypes(foodTypes.Get("value").String())
+	if err != nil {
+		println("Invalid food types: " + err.Error())
+	} else {
+		gameWorld.FoodTypes = foods
+	}
+	gameWorld.FoodPreferences = foodPreferences.Get("checked").Bool()
+	gameWorld.Sensing = sensingInput.Get("checked").Bool()
+
+	v = networkBrains.Get("value").String()
+	n, err = strconv.Atoi(v)
+	if err != nil || n < 0 || n > 100 {
+		println("Invalid percentage for network brains")
+	} else {
+		gameWorld.NetworkBrains = n
+	}
+
+	gameWorld.SexualReproduction = sexualRepro.Get("checked").Bool()
+	setRules()
+	setMutation()
+	setCompetition()
+	setBoundary()
+}
+
+func setGrowth() {
+	growth := gameWorld.Growth
+
+	model, err := world.ParseGrowthModel(growthInput.Get("value").String())
+	if err != nil {
+		println("Invalid growth: " + err.Error())
+		return
+	}
+	growth.Model = model
+
+	if growth.SpreadRate, err = strconv.Atoi(spreadRate.Get("value").String()); err != nil {
+		println("Invalid number for spread rate")
+		return
+	}
+	if growth.CarryingCapacity, err = strconv.Atoi(carryingCapacity.Get("value").String()); err != nil {
+		println("Invalid number for carrying capacity")
+		return
+	}
+
+	if err := growth.Validate(); err != nil {
+		println("Invalid growth: " + err.Error())
+		return
+	}
+	gameWorld.Growth = growth
+}
+
+func setSeed() {
+	v := seedInput.Get("value").String()
+	if v == "" {
+		gameWorld.Seed = 0
+		return
+	}
+
+	n, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		println("Invalid number for seed")
+		gameWorld.Seed = 0
+	} else {
+		gameWorld.Seed = n
+	}
+}
+
+func showParams() {
+	startingBacteria.Set("value", strconv.Itoa(gameWorld.InitialBacteria))
+	startingBugs.Set("value", strconv.Itoa(gameWorld.InitialBugCount))
+	startingPreds.Set("value", strconv.Itoa(gameWorld.InitialPredatorCount))
+	reseedRate.Set("value", strconv.Itoa(gameWorld.ReseedBacteria))
+	growthInput.Set("value", gameWorld.Growth.Model.String())
+	spreadRate.Set("value", strconv.Itoa(gameWorld.Growth.SpreadRate))
+	carryingCapacity.Set("value", strconv.Itoa(gameWorld.Growth.CarryingCapacity))
+	seedInput.Set("value", strconv.FormatUint(gameWorld.Seed, 10))
+	geometryInput.Set("value", gameWorld.ActiveGeometry().Name)
+	if gameWorld.ActiveGeometry().Name == world.CUSTOM_GEOMETRY {
+		directionsInput.Set("value", gameWorld.ActiveGeometry().DirectionsString())
+	}
+	classifierInput.Set("value", gameWorld.Classifier().Name())
+	fullHistory.Set("checked", gameWorld.KeepFullHistory)
+	recordLineage.Set("checked", gameWorld.RecordLineage)
+	sexualRepro.Set("checked", gameWorld.SexualReproduction)
+
+	lines := []string{}
+	for _, r := range gameWorld.FertilityRegions {
+		lines = append(lines, r.String())
+	}
+	fertilityRegions.Set("value", strings.Join(lines, "\n"))
+
+	lines = []string{}
+	for _, s := range gameWorld.Schedules {
+		lines = append(lines, s.String())
+	}
+	schedules.Set("value", strings.Join(lines, "\n"))
+
+	lines = []string{}
+	for _, f := range gameWorld.FoodTypes {
+		lines = append(lines, f.String())
+	}
+	foodTypes.Set("value", strings.Join(lines, "\n"))
+	foodPreferences.Set("checked", gameWorld.FoodPreferences)
+	sensingInput.Set("checked", gameWorld.Sensing)
+	networkBrains.Set("value", strconv.Itoa(gameWorld.NetworkBrains))
+
+	showRules()
+	showTerrain()
+}
+
+func saveGame(this js.Value, args []js.Value) interface{} {
+	data, err := gameWorld.MarshalSnapshot()
+	if err != nil {
+		println("Failed to save snapshot: " + err.Error())
+		return nil
+	}
+
+	downloadFile(fmt.Sprintf("wasmbugs-%d.json", gameWorld.Cycle()), "application/json", data)
+
+	return nil
+}
+
+func exportCSV(this js.Value, args []js.Value) interface{} {
+	var buf bytes.Buffer
+	if err := world.WriteHistoryCSV(&buf, gameWorld.History()); err != nil {
+		println("Failed to export history: " + err.Error())
+		return nil
+	}
+
+	downloadFile(fmt.Sprintf("wasmbugs-history-%d.csv", gameWorld.Seed), "text/csv", buf.Bytes())
+
+	return nil
+}
+
+func exportJSON(this js.Value, args []js.Value) interface{} {
+	var buf bytes.Buffer
+	if err := world.WriteHistoryJSON(&buf, gameWorld.History()); err != nil {
+		println("Failed to export history: " + err.Error())
+		return nil
+	}
+
+	downloadFile(fmt.Sprintf("wasmbugs-history-%d.json", gameWorld.Seed), "application/json", buf.Bytes())
+
+	return nil
+}
+
+func exportLineageNewick(this js.Value, args []js.Value) interface{} {
+	lineage := gameWorld.Lineage()
+	if lineage == nil {
+		println("Lineage is not being recorded")
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := lineage.WriteNewick(&buf, gameWorld.Cycle()); err != nil {
+		println("Failed to export lineage: " + err.Error())
+		return nil
+	}
+
+	downloadFile(fmt.Sprintf("wasmbugs-lineage-%d.nwk", gameWorld.Seed), "text/plain", buf.Bytes())
+
+	return nil
+}
+
+func exportLineageJSON(this js.Value, args []js.Value) interface{} {
+	lineage := gameWorld.Lineage()
+	if lineage == nil {
+		println("Lineage is not being recorded")
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := lineage.WriteJSON(&buf); err != nil {
+		println("Failed to export lineage: " + err.Error())
+		return nil
+	}
+
+	downloadFile(fmt.Sprintf("wasmbugs-lineage-%d.json", gameWorld.Seed), "application/json", buf.Bytes())
+
+	return nil
+}
+
+func loadGame(this js.Value, args []js.Value) interface{} {
+	if started {
+		println("Pause the game before loading a snapshot")
+		loadInput.Set("value", "")
+		return nil
+	}
+
+	readFile(loadInput, func(data []byte) {
+		if err := gameWorld.UnmarshalSnapshot(data); err != nil {
+			println("Failed to load snapshot: " + err.Error())
+			return
+		}
+
+		showParams()
+		selectedBug = nil
+		paused = true
+		draw()
+
+		pauseButton.Set("disabled", true)
+		startButton.Set("disabled", false)
+		resetButton.Set("disabled", false)
+	})
+
+	return nil
+}
+
+func loadFertilityMap(this js.Value, args []js.Value) interface{} {
+	readFile(fertilityMap, func(data []byte) {
+		m, err := world.LoadFertilityMap(bytes.NewReader(data))
+		if err != nil {
+			println("Failed to load fertility map: " + err.Error())
+			return
+		}
+
+		gameWorld.FertilityMap = m
+		draw()
+	})
+
+	return nil
+}
+
+func clearFertilityMap(this js.Value, args []js.Value) interface{} {
+	gameWorld.FertilityMap = nil
+	draw()
+
+	return nil
+}
+
+func setClassifier(this js.Value, args []js.Value) interface{} {
+	classifier, err := world.ClassifierByName(classifierInput.Get("value").String())
+	if err != nil {
+		println("Invalid classifier: " + err.Error())
+		return nil
+	}
+	gameWorld.SetClassifier(classifier)
+	if !started || paused {
+		draw()
+	}
+	return nil
+}
+
+func setGeometry() {
+	name := geometryInput.Get("value").String()
+	geometry, err := world.GeometryByName(name)
+	if name == world.CUSTOM_GEOMETRY {
+		geometry, err = world.ParseDirections(directionsInput.Get("value").String())
+	}
+	if err != nil {
+		println("Invalid geometry: " + err.Error())
+		return
+	}
+	gameWorld.Geometry = geometry
 }
 
 func resetGame(this js.Value, args []js.Value) interface{} {
 	setParams()
-	gameWorld.Initialize(canvas, ctx, reportCanvas, reportCtx)
+	setSeed()
+	setGeometry()
+	gameWorld.Initialize()
+	selectedBug = nil
 
 	paused = false
 
@@ -186,6 +743,23 @@ func resetGame(this js.Value, args []js.Value) interface{} {
 	return nil
 }
 
+// applySettings applies the rules, mutation and competition settings to the
+// world without resetting it, so the game can carry on under them after a
+// pause. The other settings change what the world holds, such as the kinds
+// of food in its cells, so they wait for a reset. Starting or resuming the
+// game leaves the world's settings alone, so a loaded snapshot runs on with
+// its own.
+func applySettings(this js.Value, args []js.Value) interface{} {
+	setRules()
+	setMutation()
+	setCompetition()
+	if !started {
+		draw()
+	}
+
+	return nil
+}
+
 func switchView(this js.Value, args []js.Value) interface{} {
 	if screenView == world.GAME_VIEW {
 		screenView = world.REPORT_VIEW
@@ -216,7 +790,6 @@ func startGame(this js.Value, args []js.Value) interface{} {
 	disableInputs()
 	started = true
 	paused = false
-	setParams()
 	go gameLoop()
 
 	resetButton.Set("disabled", true)
@@ -267,4 +840,5 @@ func update() error {
 
 func draw() {
 	gameWorld.Draw(screenView)
+	showBugDetails()
 }
@@ -0,0 +1,171 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"strconv"
+	"syscall/js"
+
+	"wasm-bugs/src/world"
+)
+
+// ruleParam ties a numeric input in the parameter panel to a field of
+// world.Rules.
+type ruleParam struct {
+	id    string
+	name  string
+	input js.Value
+	field func(rules *world.Rules) *int
+}
+
+var ruleParams = []*ruleParam{
+	{id: "reproduce_age", name: "reproduction age", field: func(r *world.Rules) *int { return &r.ReproduceAge }},
+	{id: "reproduce_energy", name: "reproduction energy", field: func(r *world.Rules) *int { return &r.ReproduceEnergy }},
+	{id: "max_energy", name: "max energy", field: func(r *world.Rules) *int { return &r.MaxEnergy }},
+	{id: "starting_energy", name: "starting energy", field: func(r *world.Rules) *int { return &r.StartingEnergy }},
+	{id: "bacterium_energy", name: "energy per bacterium", field: func(r *world.Rules) *int { return &r.EnergyPerBacterium }},
+	{id: "feeding_radius", name: "feeding radius", field: func(r *world.Rules) *int { return &r.FeedingRadius }},
+	{id: "mating_radius", name: "mating radius", field: func(r *world.Rules) *int { return &r.MatingRadius }},
+	{id: "history_interval", name: "history interval", field: func(r *world.Rules) *int { return &r.HistoryInterval }},
+	{id: "predator_reproduce_age", name: "predator reproduction age", field: func(r *world.Rules) *int { return &r.Predator.ReproduceAge }},
+	{id: "predator_reproduce_energy", name: "predator reproduction energy", field: func(r *world.Rules) *int { return &r.Predator.ReproduceEnergy }},
+	{id: "predator_max_energy", name: "predator max energy", field: func(r *world.Rules) *int { return &r.Predator.MaxEnergy }},
+	{id: "predator_starting_energy", name: "predator starting energy", field: func(r *world.Rules) *int { return &r.Predator.StartingEnergy }},
+	{id: "predator_bug_energy", name: "energy per bug", field: func(r *world.Rules) *int { return &r.Predator.EnergyPerBug }},
+	{id: "predator_feeding_radius", name: "predator feeding radius", field: func(r *world.Rules) *int { return &r.Predator.FeedingRadius }},
+}
+
+// Inputs for the fields of world.MutationPolicy and world.Competition,
+// which sit with the rules.
+var (
+	feedingOrder js.Value
+	exclusion    js.Value
+	stealPercent js.Value
+	noMutation   js.Value
+	mutationRate js.Value
+	mutationStep js.Value
+	geneMin      js.Value
+	geneMax      js.Value
+)
+
+func findRuleInputs(doc js.Value) bool {
+	for _, p := range ruleParams {
+		p.input = doc.Call("getElementById", p.id)
+		if p.input.IsNull() {
+			println("Failed to get " + p.id)
+			return false
+		}
+	}
+
+	for id, input := range map[string]*js.Value{
+		"feeding_order": &feedingOrder,
+		"exclusion":     &exclusion,
+		"steal_percent": &stealPercent,
+		"no_mutation":   &noMutation,
+		"mutation_rate": &mutationRate,
+		"mutation_step": &mutationStep,
+		"gene_min":      &geneMin,
+		"gene_max":      &geneMax,
+	} {
+		*input = doc.Call("getElementById", id)
+		if input.IsNull() {
+			println("Failed to get " + id)
+			return false
+		}
+	}
+	return true
+}
+
+func setRuleInputsDisabled(disabled bool) {
+	for _, p := range ruleParams {
+		p.input.Set("disabled", disabled)
+	}
+	for _, input := range []js.Value{feedingOrder, exclusion, stealPercent, noMutation, mutationRate, mutationStep, geneMin, geneMax} {
+		input.Set("disabled", disabled)
+	}
+}
+
+func showRules() {
+	for _, p := range ruleParams {
+		p.input.Set("value", strconv.Itoa(*p.field(&gameWorld.Rules)))
+	}
+
+	competition := gameWorld.Competition
+	feedingOrder.Set("value", competition.FeedingOrder.String())
+	exclusion.Set("checked", competition.Exclusion)
+	stealPercent.Set("value", strconv.Itoa(competition.StealPercent))
+
+	mutation := gameWorld.Mutation
+	noMutation.Set("checked", mutation.Off)
+	mutationRate.Set("value", strconv.FormatFloat(mutation.GeneRate, 'f', -1, 64))
+	mutationStep.Set("value", strconv.FormatFloat(mutation.StepSize, 'f', -1, 64))
+	geneMin.Set("value", strconv.Itoa(mutation.MinGene))
+	geneMax.Set("value", strconv.Itoa(mutation.MaxGene))
+}
+
+func setRules() {
+	rules := gameWorld.Rules
+	for _, p := range ruleParams {
+		n, err := strconv.Atoi(p.input.Get("value").String())
+		if err != nil {
+			println("Invalid number for " + p.name)
+			continue
+		}
+		*p.field(&rules) = n
+	}
+
+	if err := rules.Validate(); err != nil {
+		println("Invalid rules: " + err.Error())
+		return
+	}
+	gameWorld.Rules = rules
+}
+
+func setCompetition() {
+	competition := world.Competition{Exclusion: exclusion.Get("checked").Bool()}
+
+	var err error
+	if competition.FeedingOrder, err = world.ParseFeedingOrder(feedingOrder.Get("value").String()); err != nil {
+		println("Invalid feeding order: " + err.Error())
+		return
+	}
+	if competition.StealPercent, err = strconv.Atoi(stealPercent.Get("value").String()); err != nil {
+		println("Invalid number for steal percentage")
+		return
+	}
+
+	if err := competition.Validate(); err != nil {
+		println("Invalid competition: " + err.Error())
+		return
+	}
+	gameWorld.Competition = competition
+}
+
+func setMutation() {
+	mutation := world.MutationPolicy{Off: noMutation.Get("checked").Bool()}
+
+	var err error
+	if mutation.GeneRate, err = strconv.ParseFloat(mutationRate.Get("value").String(), 64); err != nil {
+		println("Invalid number for mutation rate")
+		return
+	}
+	if mutation.StepSize, err = strconv.ParseFloat(mutationStep.Get("value").String(), 64); err != nil {
+		println("Invalid number for mutation step")
+		return
+	}
+	if mutation.MinGene, err = strconv.Atoi(geneMin.Get("value").String()); err != nil {
+		println("Invalid number for minimum gene")
+		return
+	}
+	if mutation.MaxGene, err = strconv.Atoi(geneMax.Get("value").String()); err != nil {
+		println("Invalid number for maximum gene")
+		return
+	}
+
+	if err := mutation.Validate(); err != nil {
+		println("Invalid mutation policy: " + err.Error())
+		return
+	}
+	gameWorld.Mutation = mutation
+}
@@ -0,0 +1,486 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"syscall/js"
+
+	"wasm-bugs/src/world"
+)
+
+// CanvasRenderer draws a GameWorld onto the HTML canvases of the game and
+// report views.
+type CanvasRenderer struct {
+	gameCanvas   js.Value
+	gameCtx      js.Value
+	reportCanvas js.Value
+	reportCtx    js.Value
+	genomeCanvas js.Value
+	genomeCtx    js.Value
+
+	bugsBottomLine   int
+	classNames       []string
+	classBottomLines []int
+
+	fertilityMap       *world.FertilityMap
+	fertilityMapCanvas js.Value
+	terrainVersion     int // terrainCanvas is redrawn when this falls behind the terrain being drawn on
+	terrainCanvas      js.Value
+	terrain            *world.Terrain
+}
+
+func NewCanvasRenderer(gameCanvas, gameCtx, reportCanvas, reportCtx, genomeCanvas, genomeCtx js.Value, height int) *CanvasRenderer {
+	return &CanvasRenderer{
+		gameCanvas:     gameCanvas,
+		gameCtx:        gameCtx,
+		reportCanvas:   reportCanvas,
+		reportCtx:      reportCtx,
+		genomeCanvas:   genomeCanvas,
+		genomeCtx:      genomeCtx,
+		bugsBottomLine: height,
+	}
+}
+
+func (r *CanvasRenderer) canvasFor(screenView world.ScreenView) (js.Value, js.Value) {
+	if screenView == world.GAME_VIEW {
+		return r.gameCanvas, r.gameCtx
+	}
+	return r.reportCanvas, r.reportCtx
+}
+
+func (r *CanvasRenderer) DrawBackground(screenView world.ScreenView, w *world.GameWorld) error {
+	canvas, ctx := r.canvasFor(screenView)
+
+	ctx.Call("clearRect", 0, 0, canvas.Get("width").Int(), canvas.Get("height").Int())
+
+	ctx.Set("fillStyle", "black")
+	ctx.Call("fillRect", 0, 0, w.Width, w.Height)
+
+	ctx.Set("fillStyle", "gray")
+	ctx.Call("fillRect", 0, w.Height, w.Width, 40)
+	return nil
+}
+
+func (r *CanvasRenderer) DrawFertility(w *world.GameWorld) error {
+	if w.FertilityMap != nil {
+		if w.FertilityMap != r.fertilityMap {
+			r.fertilityMap = w.FertilityMap
+			r.fertilityMapCanvas = fertilityMapCanvas(w.FertilityMap)
+		}
+		r.gameCtx.Call("drawImage", r.fertilityMapCanvas, 0, 0, w.Width, w.Height)
+	}
+
+	r.gameCtx.Call("save")
+	r.gameCtx.Call("beginPath")
+	r.gameCtx.Call("rect", 0, 0, w.Width, w.Height)
+	r.gameCtx.Call("clip")
+
+	r.gameCtx.Set("fillStyle", "rgba(70, 130, 180, 0.25)")
+	for _, region := range w.FertilityRegions {
+		if region.Shape == world.CIRCLE {
+			r.gameCtx.Call("beginPath")
+			r.gameCtx.Call("arc", region.X, region.Y, region.Radius, 0, 2*math.Pi)
+			r.gameCtx.Call("fill")
+		} else {
+			r.gameCtx.Call("fillRect", region.X, region.Y, region.Width, region.Height)
+		}
+	}
+
+	r.gameCtx.Call("restore")
+	return nil
+}
+
+// fertilityMapCanvas renders a fertility map once into an offscreen canvas,
+// tinting fertile areas so it can be drawn cheaply every frame.
+func fertilityMapCanvas(m *world.FertilityMap) js.Value {
+	canvas := js.Global().Get("document").Call("createElement", "canvas")
+	canvas.Set("width", m.Width)
+	canvas.Set("height", m.Height)
+	ctx := canvas.Call("getContext", "2d")
+
+	pixels := make([]byte, len(m.Values)*4)
+	for i, v := range m.Values {
+		pixels[i*4] = 70
+		pixels[i*4+1] = 130
+		pixels[i*4+2] = 180
+		pixels[i*4+3] = v / 4
+	}
+
+	imageData := ctx.Call("createImageData", m.Width, m.Height)
+	js.CopyBytesToJS(imageData.Get("data"), pixels)
+	ctx.Call("putImageData", imageData, 0, 0)
+
+	return canvas
+}
+
+func (r *CanvasRenderer) DrawTerrain(w *world.GameWorld) error {
+	if w.Terrain == nil {
+		return nil
+	}
+
+	if w.Terrain != r.terrain || r.terrainVersion != terrainVersion {
+		r.terrain = w.Terrain
+		r.terrainVersion = terrainVersion
+		r.terrainCanvas = terrainCanvas(w.Terrain)
+	}
+	r.gameCtx.Call("save")
+	r.gameCtx.Set("imageSmoothingEnabled", false)
+	r.gameCtx.Call("drawImage", r.terrainCanvas, 0, 0, w.Width, w.Height)
+	r.gameCtx.Call("restore")
+	return nil
+}
+
+// terrainCanvas renders the walls of a terrain once into an offscreen
+// canvas, so they can be drawn cheaply every frame.
+func terrainCanvas(t *world.Terrain) js.Value {
+	canvas := js.Global().Get("document").Call("createElement", "canvas")
+	canvas.Set("width", t.Width)
+	canvas.Set("height", t.Height)
+	ctx := canvas.Call("getContext", "2d")
+
+	pixels := make([]byte, len(t.Walls)*4)
+	for i, v := range t.Walls {
+		if v != 0 {
+			pixels[i*4] = 128
+			pixels[i*4+1] = 128
+			pixels[i*4+2] = 128
+			pixels[i*4+3] = 255
+		}
+	}
+
+	imageData := ctx.Call("createImageData", t.Width, t.Height)
+	js.CopyBytesToJS(imageData.Get("data"), pixels)
+	ctx.Call("putImageData", imageData, 0, 0)
+
+	return canvas
+}
+
+func (r *CanvasRenderer) DrawCells(w *world.GameWorld) error {
+	var current byte
+	for x := range w.Width {
+		for y := range w.Height {
+			v, err := w.GetCell(x, y)
+			if err != nil {
+				return err
+			}
+
+			if v != 0 {
+				if v != current {
+					r.gameCtx.Set("fillStyle", w.FoodColor(v))
+					current = v
+				}
+				r.gameCtx.Call("fillRect", x, y, 1, 1)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *CanvasRenderer) DrawBugs(w *world.GameWorld) error {
+	for _, b := range w.Bugs() {
+		if b.Followed() {
+			drawTrail(r.gameCtx, b, w.Width, w.Height)
+		}
+	}
+
+	colors := map[string]string{}
+	for _, class := range w.Classifier().Classes() {
+		colors[class.Name] = class.Color
+	}
+
+	for _, b := range w.Bugs() {
+		drawBug(r.gameCtx, b, colors)
+		if b.Followed() {
+			drawHighlight(r.gameCtx, b, b.ID == w.FollowedID())
+		}
+	}
+
+	r.gameCtx.Set("fillStyle", "white")
+	for _, p := range w.Predators() {
+		r.gameCtx.Call("fillRect", p.X-2, p.Y-2, 5, 5)
+	}
+	return nil
+}
+
+// drawTrail draws the recent path of a followed bug, lifting the pen where
+// the bug wrapped around an edge of the world.
+func drawTrail(ctx js.Value, b *world.Bug, width, height int) {
+	trail := b.Trail()
+	if len(trail) < 2 {
+		return
+	}
+
+	ctx.Set("strokeStyle", "rgba(255, 255, 255, 0.5)")
+	ctx.Set("lineWidth", 1)
+	ctx.Call("beginPath")
+	ctx.Call("moveTo", trail[0].X, trail[0].Y)
+	for i := 1; i < len(trail); i++ {
+		dx := trail[i].X - trail[i-1].X
+		dy := trail[i].Y - trail[i-1].Y
+		if dx > width/2 || -dx > width/2 || dy > height/2 || -dy > height/2 {
+			ctx.Call("moveTo", trail[i].X, trail[i].Y)
+		} else {
+			ctx.Call("lineTo", trail[i].X, trail[i].Y)
+		}
+	}
+	ctx.Call("stroke")
+}
+
+func drawHighlight(ctx js.Value, b *world.Bug, selected bool) {
+	ctx.Set("strokeStyle", "white")
+	ctx.Set("lineWidth", 1)
+	if selected {
+		ctx.Call("strokeRect", b.X-5, b.Y-5, 10, 10)
+	} else {
+		ctx.Call("strokeRect", b.X-3, b.Y-3, 6, 6)
+	}
+}
+
+func drawBug(ctx js.Value, b *world.Bug, colors map[string]string) {
+	color, ok := colors[b.Classification]
+	if !ok {
+		color = "gray"
+	}
+	ctx.Set("fillStyle", color)
+	ctx.Call("fillRect", b.X-1, b.Y-1, 3, 3)
+}
+
+func (r *CanvasRenderer) DrawHUD(screenView world.ScreenView, w *world.GameWorld) error {
+	_, ctx := r.canvasFor(screenView)
+
+	ctx.Set("font", "20px Arial")
+	ctx.Set("fillStyle", "black")
+	ctx.Call("fillText", fmt.Sprintf("Cycle : %d", w.Cycle()), 30, w.Height+25)
+
+	ratio := float64(w.BacteriaCount()) / float64(w.Width*w.Height) * 100
+	ctx.Call("fillText", fmt.Sprintf("Bacteria : %d (%2.1f%%)", w.BacteriaCount(), ratio), 180, w.Height+25)
+
+	ctx.Call("fillText", fmt.Sprintf("Bugs : %d", len(w.Bugs())), 450, w.Height+25)
+
+	ctx.Set("font", "12px Arial")
+	ctx.Set("fillStyle", "white")
+	ctx.Call("fillText", fmt.Sprintf("Seed : %d", w.Seed), 30, w.Height+55)
+	if len(w.Predators()) > 0 || w.InitialPredatorCount > 0 {
+		ctx.Call("fillText", fmt.Sprintf("Predators : %d", len(w.Predators())), 180, w.Height+55)
+	}
+	if len(w.Schedules) > 0 {
+		ctx.Call("fillText", fmt.Sprintf("Reseed : %d", w.ReseedRate()), 330, w.Height+55)
+	}
+	return nil
+}
+
+func (r *CanvasRenderer) DrawReport(w *world.GameWorld) error {
+	if len(w.History()) == 0 {
+		r.drawGeneHistograms(w)
+		return nil
+	}
+
+	classes := w.Classifier().Classes()
+	r.resetClassBottomLines(classes, w.Height)
+
+	r.drawBugHistory(w)
+	r.drawBacteriaHistory(w)
+	for i, class := range classes {
+		r.drawClassHistory(w, i, class)
+	}
+
+	r.drawGeneHistograms(w)
+
+	return nil
+}
+
+// resetClassBottomLines starts the class charts again from the bottom
+// whenever the classifier emits a different set of classes.
+func (r *CanvasRenderer) resetClassBottomLines(classes []world.Class, height int) {
+	same := len(classes) == len(r.classNames)
+	for i := 0; same && i < len(classes); i++ {
+		same = classes[i].Name == r.classNames[i]
+	}
+	if same {
+		return
+	}
+
+	r.classNames = make([]string, len(classes))
+	r.classBottomLines = make([]int, len(classes))
+	for i, class := range classes {
+		r.classNames[i] = class.Name
+		r.classBottomLines[i] = height
+	}
+}
+
+// drawGeneHistograms draws one bar chart per turn gene showing how many bugs
+// carry each gene value.
+func (r *CanvasRenderer) drawGeneHistograms(w *world.GameWorld) {
+	const labelHeight = 16
+
+	width := r.genomeCanvas.Get("width").Int()
+	height := r.genomeCanvas.Get("height").Int()
+	r.genomeCtx.Call("clearRect", 0, 0, width, height)
+	r.genomeCtx.Set("fillStyle", "black")
+	r.genomeCtx.Call("fillRect", 0, 0, width, height)
+
+	h := w.GeneHistogram()
+	if len(h.Counts) == 0 {
+		return
+	}
+
+	bugCount := 0
+	for _, c := range h.Counts[0] {
+		bugCount += c
+	}
+	columns := (len(h.Counts) + 1) / 2
+
+	panelWidth := width / columns
+	panelHeight := height / 2
+	values := h.Max - h.Min + 1
+
+	r.genomeCtx.Set("font", "12px Arial")
+	for gene := range h.Counts {
+		left := (gene % columns) * panelWidth
+		top := (gene / columns) * panelHeight
+		bottom := top + panelHeight - labelHeight
+
+		r.genomeCtx.Set("strokeStyle", "gray")
+		r.genomeCtx.Call("strokeRect", float64(left)+0.5, float64(top)+0.5, panelWidth-1, panelHeight-1)
+		r.genomeCtx.Set("fillStyle", "white")
+		r.genomeCtx.Call("fillText", fmt.Sprintf("Turn %d", gene), left+5, top+14)
+
+		if bugCount == 0 {
+			continue
+		}
+
+		barWidth := float64(panelWidth-10) / float64(values)
+		chartHeight := float64(panelHeight - labelHeight - 20)
+		for v := h.Min; v <= h.Max; v++ {
+			count := h.Count(gene, v)
+			barHeight := chartHeight * float64(count) / float64(bugCount)
+			x := float64(left+5) + float64(v-h.Min)*barWidth
+
+			if v == 0 {
+				r.genomeCtx.Set("fillStyle", "gray")
+			} else {
+				r.genomeCtx.Set("fillStyle", "steelblue")
+			}
+			r.genomeCtx.Call("fillRect", x+1, float64(bottom)-barHeight, barWidth-2, barHeight)
+		}
+
+		r.genomeCtx.Set("fillStyle", "lightgray")
+		r.genomeCtx.Call("fillText", strconv.Itoa(h.Min), left+5, bottom+13)
+		maxText := strconv.Itoa(h.Max)
+		maxWidth := r.genomeCtx.Call("measureText", maxText).Get("width").Float()
+		r.genomeCtx.Call("fillText", maxText, float64(left+panelWidth-5)-maxWidth, bottom+13)
+	}
+}
+
+func (r *CanvasRenderer) drawBugHistory(w *world.GameWorld) {
+	history := w.History()
+
+	r.reportCtx.Set("strokeStyle", "lightgray")
+	r.reportCtx.Call("beginPath")
+	r.reportCtx.Call("moveTo", 0, r.bugsBottomLine)
+	r.reportCtx.Call("lineTo", w.Width, r.bugsBottomLine)
+	r.reportCtx.Call("stroke")
+
+	r.reportCtx.Set("strokeStyle", "white")
+	r.reportCtx.Call("beginPath")
+
+	startIndex := 0
+	if len(history) > w.Width {
+		startIndex = len(history) - w.Width
+	}
+
+	for i := startIndex; i < len(history); i++ {
+		h := history[i]
+		x := i - startIndex
+		y := r.bugsBottomLine - h.BugCount - 2
+		if len(r.classBottomLines) > 0 && y < r.classBottomLines[0]+20 {
+			r.classBottomLines[0] = y - 30
+		}
+		if i == 0 {
+			r.reportCtx.Call("moveTo", x, y)
+		} else {
+			r.reportCtx.Call("lineTo", x, y)
+		}
+	}
+	r.reportCtx.Call("stroke")
+}
+
+// drawClassHistory draws the count of one class over time, stacked above
+// the class before it. When the line climbs too close to the next class, that
+// class is pushed further up.
+func (r *CanvasRenderer) drawClassHistory(w *world.GameWorld, index int, class world.Class) {
+	history := w.History()
+	bottomLine := r.classBottomLines[index]
+
+	r.reportCtx.Set("strokeStyle", "lightgray")
+	r.reportCtx.Call("beginPath")
+	r.reportCtx.Call("moveTo", 0, bottomLine)
+	r.reportCtx.Call("lineTo", w.Width, bottomLine)
+	r.reportCtx.Call("stroke")
+
+	r.reportCtx.Set("strokeStyle", class.Color)
+	r.reportCtx.Call("beginPath")
+
+	startIndex := 0
+	if len(history) > w.Width {
+		startIndex = len(history) - w.Width
+	}
+
+	var x int
+	for i := startIndex; i < len(history); i++ {
+		h := history[i]
+		x = i - startIndex
+		y := bottomLine - h.Classes[class.Name] - 2
+		if index+1 < len(r.classBottomLines) && y < r.classBottomLines[index+1]+20 {
+			r.classBottomLines[index+1] = y - 30
+		}
+		if i == 0 {
+			r.reportCtx.Call("moveTo", x, y)
+		} else {
+			r.reportCtx.Call("lineTo", x, y)
+		}
+	}
+	r.reportCtx.Call("stroke")
+
+	r.reportCtx.Set("font", "12px Arial")
+	r.reportCtx.Set("fillStyle", class.Color)
+	text := strconv.Itoa(history[len(history)-1].Classes[class.Name])
+	textMetrics := r.reportCtx.Call("measureText", text)
+	x = x - int(textMetrics.Get("width").Float()) - 5
+	if x < 1 {
+		x = 1
+	}
+	r.reportCtx.Call("fillText", text, x, bottomLine-5)
+}
+
+func (r *CanvasRenderer) drawBacteriaHistory(w *world.GameWorld) {
+	history := w.History()
+
+	r.reportCtx.Set("strokeStyle", "green")
+	r.reportCtx.Call("beginPath")
+
+	startIndex := 0
+	if len(history) > w.Width {
+		startIndex = len(history) - w.Width
+	}
+
+	for i := startIndex; i < len(history); i++ {
+		x := i - startIndex
+		h := history[i]
+		gap := float64(w.Height) / 50
+		y := w.Height - int((h.BacteriaPercent*100)*gap)
+		if y < r.bugsBottomLine {
+			r.bugsBottomLine = y - 25
+		}
+		if i == 0 {
+			r.reportCtx.Call("moveTo", x, y)
+		} else {
+			r.reportCtx.Call("lineTo", x, y)
+		}
+	}
+	r.reportCtx.Call("stroke")
+}
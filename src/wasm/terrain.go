@@ -0,0 +1,127 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"bytes"
+	"syscall/js"
+
+	"wasm-bugs/src/world"
+)
+
+// Inputs for the terrain and boundary of the world. Walls can be loaded
+// from an image or drawn straight onto the game canvas.
+var (
+	boundaryInput   js.Value
+	terrainInput    js.Value
+	clearTerrainBtn js.Value
+	drawWalls       js.Value
+	paintingWalls   bool
+	erasingWalls    bool
+	terrainVersion  int // bumped whenever walls are drawn, so the renderer redraws them
+)
+
+// wallBrush is how far from the mouse walls are painted, so 1 paints 3x3
+// cells.
+const wallBrush = 1
+
+func findTerrainInputs(doc js.Value) bool {
+	for id, input := range map[string]*js.Value{
+		"boundary":          &boundaryInput,
+		"terrain":           &terrainInput,
+		"clear-terrain-btn": &clearTerrainBtn,
+		"draw_walls":        &drawWalls,
+	} {
+		*input = doc.Call("getElementById", id)
+		if input.IsNull() {
+			println("Failed to get " + id)
+			return false
+		}
+	}
+
+	terrainInput.Call("addEventListener", "change", js.FuncOf(loadTerrain))
+	clearTerrainBtn.Call("addEventListener", "click", js.FuncOf(clearTerrain))
+	canvas.Call("addEventListener", "mousedown", js.FuncOf(startPaintingWalls))
+	canvas.Call("addEventListener", "mousemove", js.FuncOf(paintWalls))
+	js.Global().Get("window").Call("addEventListener", "mouseup", js.FuncOf(stopPaintingWalls))
+	return true
+}
+
+func setTerrainInputsDisabled(disabled bool) {
+	boundaryInput.Set("disabled", disabled)
+}
+
+func showTerrain() {
+	boundaryInput.Set("value", gameWorld.Boundary.String())
+}
+
+func setBoundary() {
+	boundary, err := world.ParseBoundary(boundaryInput.Get("value").String())
+	if err != nil {
+		println("Invalid boundary: " + err.Error())
+		return
+	}
+	gameWorld.Boundary = boundary
+}
+
+func loadTerrain(this js.Value, args []js.Value) interface{} {
+	readFile(terrainInput, func(data []byte) {
+		t, err := world.LoadTerrain(bytes.NewReader(data))
+		if err == nil {
+			err = t.Validate()
+		}
+		if err != nil {
+			println("Failed to load terrain: " + err.Error())
+			return
+		}
+
+		gameWorld.Terrain = t
+		draw()
+	})
+
+	return nil
+}
+
+func clearTerrain(this js.Value, args []js.Value) interface{} {
+	gameWorld.Terrain = nil
+	draw()
+
+	return nil
+}
+
+func startPaintingWalls(this js.Value, args []js.Value) interface{} {
+	if !drawWalls.Get("checked").Bool() {
+		return nil
+	}
+
+	paintingWalls = true
+	erasingWalls = args[0].Get("shiftKey").Bool()
+	return paintWalls(this, args)
+}
+
+func stopPaintingWalls(this js.Value, args []js.Value) interface{} {
+	paintingWalls = false
+	return nil
+}
+
+// paintWalls builds walls under the mouse while the button is held down, or
+// knocks them down if shift was held when painting started.
+func paintWalls(this js.Value, args []js.Value) interface{} {
+	if !paintingWalls {
+		return nil
+	}
+
+	x, y := canvasPosition(args[0])
+	for dy := -wallBrush; dy <= wallBrush; dy++ {
+		for dx := -wallBrush; dx <= wallBrush; dx++ {
+			gameWorld.SetWall(x+dx, y+dy, !erasingWalls)
+		}
+	}
+	terrainVersion++
+
+	if !started || paused {
+		draw()
+	}
+	return nil
+}
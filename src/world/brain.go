@@ -0,0 +1,226 @@
+package world
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+)
+
+// Brain decides which way a bug turns each cycle. Its genes are part of the
+// genome of the bug, so they are inherited, mutated and crossed over along
+// with the rest of it.
+type Brain interface {
+	Name() string
+	SelectTurn(rng *rand.Rand, b *Bug) int
+	TurnProbabilities(b *Bug) []float64 // chance of each turn, where turn 0 keeps the bug moving forward
+	Genome(b *Bug) [][]int              // the genes that evolve, aliased so changing them changes the bug
+	Clone() Brain                       // a copy for a child of the bug
+}
+
+const (
+	GENE_BRAIN    = "genes"
+	NETWORK_BRAIN = "network"
+)
+
+// BrainNames lists the names of the built-in brains.
+func BrainNames() []string {
+	return []string{GENE_BRAIN, NETWORK_BRAIN}
+}
+
+// GeneBrain is Palmiter's original scheme, picking a turn at random with a
+// chance weighted by the turn genes of the bug, and by its sense genes when
+// the world has Sensing on.
+type GeneBrain struct{}
+
+func (g GeneBrain) Name() string {
+	return GENE_BRAIN
+}
+
+// SelectTurn picks the first turn whose weight exceeds a random number below
+// the total weight. This is not a cumulative roulette, but it is the way the
+// original worked and the classes bugs evolve into depend on it.
+func (g GeneBrain) SelectTurn(rng *rand.Rand, b *Bug) int {
+	weights, total := b.turnWeights()
+	if total == 0 {
+		return rng.IntN(len(weights))
+	} else {
+		n := rng.IntN(total)
+		for i := range weights {
+			if n < weights[i] {
+				return i
+			}
+		}
+	}
+
+	return len(weights) - 1
+}
+
+// TurnProbabilities follows SelectTurn, so a turn only gains the share its
+// weight adds over the largest weight before it, and the last turn takes
+// whatever is left.
+func (g GeneBrain) TurnProbabilities(b *Bug) []float64 {
+	weights, total := b.turnWeights()
+	result := make([]float64, len(weights))
+	if total == 0 {
+		for i := range result {
+			result[i] = 1.0 / float64(len(result))
+		}
+		return result
+	}
+
+	last := len(result) - 1
+	covered := 0
+	for i := range last {
+		if weights[i] > covered {
+			result[i] = float64(weights[i]-covered) / float64(total)
+			covered = weights[i]
+		}
+	}
+	result[last] = float64(total-covered) / float64(total)
+
+	return result
+}
+
+func (g GeneBrain) Genome(b *Bug) [][]int {
+	return [][]int{b.geneValue, b.senseGene}
+}
+
+func (g GeneBrain) Clone() Brain {
+	return g
+}
+
+const (
+	NETWORK_INPUTS       = 6    // energy, age, food ahead, to the left and to the right, and a bias
+	NETWORK_HIDDEN       = 4    // neurons in the hidden layer
+	NETWORK_WEIGHT_SCALE = 2.0  // a gene of 2 is a weight of 1
+	NETWORK_ENERGY_SCALE = 1000 // energy at which the energy input reaches one half
+	NETWORK_AGE_SCALE    = 800  // age at which the age input reaches one half
+)
+
+// NetworkBrain is a small feed-forward network. It sees the energy and age
+// of the bug and the food it senses ahead, to its left and to its right,
+// passes them through one hidden layer, and picks a turn with the softmax of
+// one output per turn. Its weights are whole-number genes, so the mutation
+// policy works on them as it does on turn genes.
+//
+// A bug steered by a network still carries the turn genes it started with,
+// but they no longer change, so classifiers read its turn probabilities
+// instead and the gene histogram leaves it out.
+type NetworkBrain struct {
+	weights []int // input to hidden weights, then for each turn a bias and hidden to output weights
+}
+
+// NewNetworkBrain returns a network for a geometry with the given number of
+// turns, with random weights in the same range as new turn genes.
+func NewNetworkBrain(rng *rand.Rand, turns int) *NetworkBrain {
+	result := &NetworkBrain{weights: make([]int, networkSize(turns))}
+	for i := range result.weights {
+		result.weights[i] = rng.IntN(4) - 2
+	}
+	return result
+}
+
+func networkSize(turns int) int {
+	return NETWORK_INPUTS*NETWORK_HIDDEN + turns*(NETWORK_HIDDEN+1)
+}
+
+func (n *NetworkBrain) Name() string {
+	return NETWORK_BRAIN
+}
+
+// Weights returns a copy of the genes of the network.
+func (n *NetworkBrain) Weights() []int {
+	return append([]int{}, n.weights...)
+}
+
+func (n *NetworkBrain) SelectTurn(rng *rand.Rand, b *Bug) int {
+	probabilities := n.TurnProbabilities(b)
+	r := rng.Float64()
+	for i, p := range probabilities {
+		if r < p {
+			return i
+		}
+		r -= p
+	}
+	return len(probabilities) - 1
+}
+
+func (n *NetworkBrain) TurnProbabilities(b *Bug) []float64 {
+	inputs := []float64{
+		squash(b.Energy, NETWORK_ENERGY_SCALE),
+		squash(b.Age, NETWORK_AGE_SCALE),
+		0, 0, 0,
+		1,
+	}
+	for i, food := range b.sensed {
+		inputs[2+i] = float64(food) / 9 // out of the nine cells of a sensed patch
+	}
+
+	k := 0
+	next := func() float64 {
+		k++
+		return float64(n.weights[k-1]) / NETWORK_WEIGHT_SCALE
+	}
+
+	hidden := make([]float64, NETWORK_HIDDEN)
+	for h := range hidden {
+		sum := 0.0
+		for _, in := range inputs {
+			sum += in * next()
+		}
+		hidden[h] = math.Tanh(sum)
+	}
+
+	result := make([]float64, (len(n.weights)-k)/(NETWORK_HIDDEN+1))
+	largest := math.Inf(-1)
+	for t := range result {
+		sum := next()
+		for _, h := range hidden {
+			sum += h * next()
+		}
+		result[t] = sum
+		largest = max(largest, sum)
+	}
+
+	total := 0.0
+	for t := range result {
+		result[t] = math.Exp(result[t] - largest)
+		total += result[t]
+	}
+	for t := range result {
+		result[t] /= total
+	}
+	return result
+}
+
+func (n *NetworkBrain) Genome(b *Bug) [][]int {
+	return [][]int{n.weights}
+}
+
+func (n *NetworkBrain) Clone() Brain {
+	return &NetworkBrain{weights: append([]int{}, n.weights...)}
+}
+
+// squash maps a count of zero or more into 0..1, reaching one half at scale.
+func squash(value, scale int) float64 {
+	v := float64(max(0, value))
+	return v / (v + float64(scale))
+}
+
+// restoreBrain rebuilds the brain of a bug read from a snapshot, returning
+// nil for a GeneBrain.
+func restoreBrain(name string, genes []int, turns int) (Brain, error) {
+	switch name {
+	case "", GENE_BRAIN:
+		if len(genes) != 0 {
+			return nil, fmt.Errorf("gene brain has %d extra genes", len(genes))
+		}
+		return nil, nil
+	case NETWORK_BRAIN:
+		if len(genes) != networkSize(turns) {
+			return nil, fmt.Errorf("network brain has %d weights, expected %d", len(genes), networkSize(turns))
+		}
+		return &NetworkBrain{weights: genes}, nil
+	}
+	return nil, fmt.Errorf("unknown brain %q", name)
+}
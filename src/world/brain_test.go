@@ -0,0 +1,143 @@
+package world
+
+import (
+	"math"
+	"math/rand/v2"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func networkBug(rng *rand.Rand) *Bug {
+	b := bugWithGenes(1, 1, 1, 1, 1, 1)
+	b.brain = NewNetworkBrain(rng, 6)
+	return b
+}
+
+func TestNetworkTurnProbabilities(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 1))
+	b := networkBug(rng)
+	b.Energy, b.Age = 500, 100
+	b.sensed = []int{3, 0, 9}
+
+	probabilities := b.TurnProbabilities()
+	if len(probabilities) != 6 {
+		t.Fatalf("expected 6 probabilities, got %d", len(probabilities))
+	}
+	total := 0.0
+	for _, p := range probabilities {
+		total += p
+	}
+	if math.Abs(total-1) > 1e-9 {
+		t.Errorf("expected probabilities to total 1, got %f", total)
+	}
+
+	for range 100 {
+		if turn := b.selectTurn(rng); turn < 0 || turn >= 6 {
+			t.Fatalf("turn %d out of range", turn)
+		}
+	}
+}
+
+func TestNetworkBrainEvolves(t *testing.T) {
+	rng := rand.New(rand.NewPCG(2, 2))
+	parent := networkBug(rng)
+	parent.ID = 1
+
+	child := parent.NewBugFrom()
+	weights := parent.brain.(*NetworkBrain).Weights()
+	if !slices.Equal(child.brain.(*NetworkBrain).Weights(), weights) {
+		t.Fatalf("expected child to inherit the network weights")
+	}
+
+	MutationPolicy{GeneRate: 1}.Apply(rng, child, 1)
+	if !slices.Equal(parent.brain.(*NetworkBrain).Weights(), weights) {
+		t.Errorf("mutating the child changed the parent's network")
+	}
+	if !slices.Equal(child.GeneValues(), parent.GeneValues()) {
+		t.Errorf("expected the turn genes of a network bug not to mutate, got %v", child.GeneValues())
+	}
+	for i, v := range child.brain.(*NetworkBrain).Weights() {
+		if v != weights[i]+1 {
+			t.Fatalf("expected weight %d to step from %d to %d, got %d", i, weights[i], weights[i]+1, v)
+		}
+	}
+
+	mate := networkBug(rng)
+	mate.ID = 2
+	child.Crossover(rng, mate)
+	last := len(weights) - 1
+	if child.brain.(*NetworkBrain).weights[last] != mate.brain.(*NetworkBrain).weights[last] {
+		t.Errorf("expected crossover to take the last weight from the mate")
+	}
+}
+
+func TestMixedBrainsSnapshot(t *testing.T) {
+	original := NewGameWorld(100, 100)
+	original.Seed = 17
+	original.InitialBugCount = 40
+	original.NetworkBrains = 50
+	original.Initialize()
+
+	counts := map[string]int{}
+	for _, b := range original.Bugs() {
+		counts[b.Brain().Name()]++
+	}
+	if counts[GENE_BRAIN] == 0 || counts[NETWORK_BRAIN] == 0 {
+		t.Fatalf("expected both kinds of brain, got %v", counts)
+	}
+
+	for range 300 {
+		original.Next()
+	}
+	data, err := original.MarshalSnapshot()
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	restored := NewGameWorld(10, 10)
+	if err := restored.UnmarshalSnapshot(data); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	for range 300 {
+		original.Next()
+		restored.Next()
+	}
+	if !reflect.DeepEqual(original.History(), restored.History()) {
+		t.Errorf("expected the restored world to follow the same history")
+	}
+}
+
+func TestMatesShareABrain(t *testing.T) {
+	w := NewGameWorld(20, 20)
+	w.InitialBugCount = 0
+	w.Initialize()
+
+	genes, network := bugWithGenes(1, 1, 1, 1, 1, 1), networkBug(w.rng)
+	for _, b := range []*Bug{genes, network} {
+		b.X, b.Y = 10, 10
+		b.Age = w.Rules.ReproduceAge + 1
+		b.Energy = w.Rules.ReproduceEnergy + 1
+	}
+	w.bugs = []*Bug{genes, network}
+
+	if mates := w.findMates(); len(mates) != 0 {
+		t.Errorf("expected bugs with different brains not to mate, got %d pairs", len(mates)/2)
+	}
+}
+
+func TestNetworkBugsClassifiedByTheirTurns(t *testing.T) {
+	b := bugWithGenes(1, 1, 1, 1, 1, 1) // an even spread of turns, if its genes were read
+	brain := &NetworkBrain{weights: make([]int, networkSize(6))}
+	brain.weights[NETWORK_INPUTS*NETWORK_HIDDEN] = 20 // a strong bias towards going straight on
+	b.brain = brain
+
+	if class := (&ForwardClassifier{}).Classify(b); class != YELLOW {
+		t.Errorf("expected a network that goes straight on to be %s, got %s", YELLOW, class)
+	}
+
+	histogram := NewGeneHistogram([]*Bug{b, bugWithGenes(2, 0, 0, 0, 0, 0)})
+	if histogram.Count(0, 1) != 0 || histogram.Count(0, 2) != 1 {
+		t.Errorf("expected the network bug to be left out of the gene histogram, got %v", histogram.Counts)
+	}
+}
@@ -1,9 +1,7 @@
 package world
 
 import (
-	"log/slog"
 	"math/rand/v2"
-	"syscall/js"
 )
 
 const (
@@ -13,157 +11,285 @@ const (
 	RED     = "Red"
 )
 
+// TRAIL_LENGTH is the number of recent positions kept for a followed bug
+const TRAIL_LENGTH = 100
+
+type Point struct {
+	X int
+	Y int
+}
+
 type Bug struct {
+	ID       int
+	ParentID int // 0 for the bugs a world starts with
+	MateID   int // 0 unless the bug was bred by crossover
+
 	X int
 	Y int
 
 	Age            int
 	Energy         int
 	Classification string
+	FoodPreference int // index into GameWorld.FoodNames of the food the bug digests best
 
 	direction      int
-	geneValue      [6]int
-	geneWeight     [6]int
+	geneValue      []int
+	geneWeight     []int
 	totalOfWeights int
+	senseGene      []int // how strongly food ahead, to the left and to the right draws the bug, empty without sensing
+	sensed         []int // the food last sensed ahead, to the left and to the right
+	brain          Brain // nil for a GeneBrain
+
+	followed bool
+	trail    []Point
 }
 
-func NewBug(x, y int) *Bug {
+func NewBug(rng *rand.Rand, x, y, energy, genomeLength int) *Bug {
 	result := &Bug{
 		X:         x,
 		Y:         y,
-		Energy:    400,
+		Energy:    energy,
 		Age:       0,
-		direction: rand.IntN(6),
+		direction: rng.IntN(genomeLength),
+		geneValue: make([]int, genomeLength),
 	}
 
-	result.totalOfWeights = 0
-	for i := range 6 {
-		result.geneValue[i] = rand.IntN(4) - 2
-		result.geneWeight[i] = result.geneValue[i] * result.geneValue[i]
-		result.totalOfWeights += result.geneWeight[i]
+	for i := range result.geneValue {
+		result.geneValue[i] = rng.IntN(4) - 2
 	}
-	result.SetClassification()
+	result.updateWeights()
 
 	return result
 }
 
 func (b *Bug) NewBugFrom() *Bug {
 	result := &Bug{
-		X:         b.X,
-		Y:         b.Y,
-		direction: b.direction,
-		Energy:    b.Energy / 2,
-		Age:       0,
+		ParentID:       b.ID,
+		X:              b.X,
+		Y:              b.Y,
+		direction:      b.direction,
+		Energy:         b.Energy / 2,
+		Age:            0,
+		followed:       b.followed,
+		FoodPreference: b.FoodPreference,
 	}
 
-	result.geneValue = [6]int{}
-	result.geneWeight = [6]int{}
-	result.totalOfWeights = 0
-	for i := range 6 {
-		result.geneValue[i] = b.geneValue[i]
-		result.geneWeight[i] = result.geneValue[i] * result.geneValue[i]
-		result.totalOfWeights += result.geneWeight[i]
+	if b.followed {
+		result.trail = append([]Point{}, b.trail...)
+	}
+
+	result.geneValue = append([]int{}, b.geneValue...)
+	if len(b.senseGene) > 0 {
+		result.senseGene = append([]int{}, b.senseGene...)
+	}
+	if b.brain != nil {
+		result.brain = b.brain.Clone()
+	}
+	result.updateWeights()
+
+	return result
+}
+
+// Mutate changes one random gene by delta, the default MutationPolicy.
+func (b *Bug) Mutate(rng *rand.Rand, delta int) {
+	MutationPolicy{}.Apply(rng, b, delta)
+}
+
+// Crossover replaces the genes of b from a random cut point onwards with the
+// genes of mate, so the bug carries a mix of both genomes. The food
+// preference comes from either bug with even chances.
+func (b *Bug) Crossover(rng *rand.Rand, mate *Bug) {
+	genes, mateGenes := b.genes(), mate.genes()
+	size := min(len(genes), len(mateGenes))
+	cut := 0
+	if size > 1 {
+		cut = 1 + rng.IntN(size-1)
+	}
+	for i := cut; i < size; i++ {
+		*genes[i] = *mateGenes[i]
+	}
+	if b.FoodPreference != mate.FoodPreference && rng.IntN(2) == 0 {
+		b.FoodPreference = mate.FoodPreference
 	}
-	result.SetClassification()
+	b.MateID = mate.ID
+	b.updateWeights()
+}
 
+// genes returns the genes of the bug that evolve, as laid out by its brain.
+func (b *Bug) genes() []*int {
+	result := []*int{}
+	for _, part := range b.Brain().Genome(b) {
+		for i := range part {
+			result = append(result, &part[i])
+		}
+	}
 	return result
 }
 
-func (b *Bug) Mutate(delta int) {
+// Brain returns what decides which way the bug turns.
+func (b *Bug) Brain() Brain {
+	if b.brain == nil {
+		return GeneBrain{}
+	}
+	return b.brain
+}
+
+// updateWeights recalculates the turn weights from the gene values, along
+// with the classification that depends on them.
+func (b *Bug) updateWeights() {
+	b.geneWeight = make([]int, len(b.geneValue))
 	b.totalOfWeights = 0
-	n := rand.IntN(6)
-	b.geneValue[n] += delta
-	for i := range 6 {
-		b.geneWeight[i] = b.geneValue[i] * b.geneValue[i]
+	for i, v := range b.geneValue {
+		b.geneWeight[i] = v * v
 		b.totalOfWeights += b.geneWeight[i]
 	}
 	b.SetClassification()
 }
 
-func (b *Bug) selectTurn() int {
-	if b.totalOfWeights == 0 {
-		return rand.IntN(6)
-	} else {
-		n := rand.IntN(b.totalOfWeights)
-		for i := range 6 {
-			if n < b.geneWeight[i] {
-				return i
-			}
-		}
+func (b *Bug) Direction() int {
+	return b.direction
+}
+
+func (b *Bug) GeneValues() []int {
+	return append([]int{}, b.geneValue...)
+}
+
+func (b *Bug) GeneWeights() []int {
+	return append([]int{}, b.geneWeight...)
+}
+
+// SenseGenes returns how strongly food ahead, to the left and to the right
+// draws the bug, or nil for a bug that cannot sense.
+func (b *Bug) SenseGenes() []int {
+	if len(b.senseGene) == 0 {
+		return nil
 	}
+	return append([]int{}, b.senseGene...)
+}
 
-	return 5
+// Sensed returns the food the bug last sensed ahead, to the left and to the
+// right, or nil for a bug that cannot sense.
+func (b *Bug) Sensed() []int {
+	return b.sensed
 }
 
-func (b *Bug) move(width, height int) (int, int) {
-	turn := b.selectTurn()
-	b.direction = (b.direction + turn) % 6
+// TurnProbabilities returns the chance of each turn being picked by
+// selectTurn, where turn 0 keeps the bug moving forward.
+func (b *Bug) TurnProbabilities() []float64 {
+	return b.Brain().TurnProbabilities(b)
+}
 
-	x := b.X
-	y := b.Y
-	switch b.direction {
-	case 0:
-		y += 2
-	case 1:
-		x += 2
-		y += 1
-	case 2:
-		x += 2
-		y += -1
-	case 3:
-		y += -2
-	case 4:
-		x += -2
-		y += -1
-	case 5:
-		x += -2
-		y += 1
-	default:
-		slog.Error("invalid direction", "direction", b.direction)
+// turnWeights returns the weight of each turn. For a bug that senses food,
+// each sense gene adds its value times the food sensed to the weight of
+// going straight on, turning left (turn 1) or turning right (the last turn).
+func (b *Bug) turnWeights() ([]int, int) {
+	if len(b.senseGene) == 0 || len(b.sensed) != len(b.senseGene) {
+		return b.geneWeight, b.totalOfWeights
 	}
 
-	if x < 0 {
-		x += width
-	} else if x >= width {
-		x -= width
+	weights := append([]int{}, b.geneWeight...)
+	turns := []int{0, 1, len(weights) - 1}
+	for i, turn := range turns {
+		weights[turn] = max(0, weights[turn]+b.senseGene[i]*b.sensed[i])
 	}
-	if y < 0 {
-		y += height
-	} else if y >= height {
-		y -= height
+
+	total := 0
+	for _, w := range weights {
+		total += w
 	}
+	return weights, total
+}
 
-	return x, y
+// Followed reports whether the bug is the followed bug or one of its
+// descendants.
+func (b *Bug) Followed() bool {
+	return b.followed
 }
 
-func (b *Bug) SetClassification() {
-	forwardMove := (float64(b.geneWeight[0]) / float64(b.totalOfWeights)) * 100
-	b.Classification = RED
-	if forwardMove > 80 {
-		b.Classification = YELLOW
-	} else if forwardMove > 50 {
-		b.Classification = CYAN
-	} else if forwardMove > 25 {
-		b.Classification = MAGENTA
+// Trail returns the recent positions of a followed bug, oldest first.
+func (b *Bug) Trail() []Point {
+	return b.trail
+}
+
+func (b *Bug) recordTrail() {
+	b.trail = append(b.trail, Point{X: b.X, Y: b.Y})
+	if len(b.trail) > TRAIL_LENGTH {
+		b.trail = b.trail[len(b.trail)-TRAIL_LENGTH:]
 	}
 }
 
-func (b *Bug) Update(width, height int) {
-	b.X, b.Y = b.move(width, height)
+func (b *Bug) selectTurn(rng *rand.Rand) int {
+	return b.Brain().SelectTurn(rng, b)
+}
 
-	b.Age++
-	b.Energy--
+// move turns the bug and returns the position it heads to next, which lies
+// outside the world if the bug leaves an absorbing edge.
+func (b *Bug) move(rng *rand.Rand, geometry Geometry, width, height int, boundary Boundary) (int, int) {
+	turn := b.selectTurn(rng)
+	b.direction = (b.direction + turn) % len(geometry.Directions)
+
+	offset := geometry.Directions[b.direction]
+	x := b.X + offset.X
+	y := b.Y + offset.Y
+
+	switch boundary {
+	case ABSORB_BOUNDARY:
+		return x, y
+	case REFLECT_BOUNDARY:
+		return b.reflect(geometry, x, y, width, height)
+	}
+
+	// Steps can be longer than a narrow world, so wrap them whole.
+	return (x%width + width) % width, (y%height + height) % height
 }
 
-func (b *Bug) Draw(ctx js.Value) {
-	if b.Classification == YELLOW {
-		ctx.Set("fillStyle", "yellow")
-	} else if b.Classification == CYAN {
-		ctx.Set("fillStyle", "cyan")
-	} else if b.Classification == MAGENTA {
-		ctx.Set("fillStyle", "magenta")
-	} else {
-		ctx.Set("fillStyle", "red")
+// SetClassification classifies the bug with Palmiter's forward share scheme.
+// A GameWorld reclassifies its bugs with its own Classifier.
+func (b *Bug) SetClassification() {
+	b.Classification = (&ForwardClassifier{}).Classify(b)
+}
+
+// reflect bounces a bug heading to x, y off the edges of the world, turning
+// it to the heading that mirrors its own across each edge it would cross. A
+// bug with no mirrored heading stays where it is.
+func (b *Bug) reflect(geometry Geometry, x, y, width, height int) (int, int) {
+	offset := geometry.Directions[b.direction]
+	if x < 0 || x >= width {
+		offset.X = -offset.X
+	}
+	if y < 0 || y >= height {
+		offset.Y = -offset.Y
+	}
+
+	for i, d := range geometry.Directions {
+		if d == offset {
+			b.direction = i
+			x, y = b.X+d.X, b.Y+d.Y
+			if x >= 0 && x < width && y >= 0 && y < height {
+				return x, y
+			}
+			break
+		}
 	}
-	ctx.Call("fillRect", b.X-1, b.Y-1, 3, 3)
+	return b.X, b.Y
+}
+
+// Update turns and moves the bug in a world whose edges behave as boundary.
+// The bug stays where it is, after turning, when blocked reports the cell it
+// heads to as taken; a nil blocked lets it move anywhere. Update reports
+// false when the bug leaves an absorbing edge, which leaves it where it was
+// with no energy left.
+func (b *Bug) Update(rng *rand.Rand, geometry Geometry, width, height int, boundary Boundary, blocked func(x, y int) bool) bool {
+	x, y := b.move(rng, geometry, width, height, boundary)
+	b.Age++
+	if boundary == ABSORB_BOUNDARY && (x < 0 || x >= width || y < 0 || y >= height) {
+		b.Energy = 0
+		return false
+	}
+
+	if blocked == nil || !blocked(x, y) {
+		b.X, b.Y = x, y
+	}
+	b.Energy--
+	return true
 }
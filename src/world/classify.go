@@ -0,0 +1,327 @@
+package world
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Class is one of the buckets a Classifier sorts bugs into.
+type Class struct {
+	Name  string `json:"name"`
+	Color string `json:"color"` // CSS color used to draw the class
+}
+
+// Classifier sorts bugs into classes by their genome. Fit is called with the
+// whole population at every history sample, which lets schemes that depend
+// on the population adapt, and Classify is then called for each bug. Reset
+// forgets whatever Fit learned, ready for a new run.
+type Classifier interface {
+	Name() string
+	Classes() []Class // in the order the report stacks them, bottom first
+	Fit(bugs []*Bug)
+	Classify(b *Bug) string
+	Reset()
+}
+
+const (
+	FORWARD_CLASSIFIER   = "forward"
+	TURN_BIAS_CLASSIFIER = "turn-bias"
+	ENTROPY_CLASSIFIER   = "entropy"
+	KMEANS_CLASSIFIER    = "kmeans"
+)
+
+// ClassifierNames lists the names of the built-in classifiers.
+func ClassifierNames() []string {
+	return []string{FORWARD_CLASSIFIER, TURN_BIAS_CLASSIFIER, ENTROPY_CLASSIFIER, KMEANS_CLASSIFIER}
+}
+
+// ClassifierByName returns a new instance of one of the built-in classifiers.
+func ClassifierByName(name string) (Classifier, error) {
+	switch name {
+	case FORWARD_CLASSIFIER:
+		return &ForwardClassifier{}, nil
+	case TURN_BIAS_CLASSIFIER:
+		return &TurnBiasClassifier{}, nil
+	case ENTROPY_CLASSIFIER:
+		return &EntropyClassifier{}, nil
+	case KMEANS_CLASSIFIER:
+		return NewKMeansClassifier(4), nil
+	}
+	return nil, fmt.Errorf("unknown classifier %q", name)
+}
+
+// ClassColor returns the color of the named class, or gray if the
+// classifier does not emit it.
+func ClassColor(c Classifier, name string) string {
+	for _, class := range c.Classes() {
+		if class.Name == name {
+			return class.Color
+		}
+	}
+	return "gray"
+}
+
+// ForwardClassifier is Palmiter's original scheme, bucketing bugs by their
+// chance of moving straight ahead.
+type ForwardClassifier struct{}
+
+func (c *ForwardClassifier) Name() string {
+	return FORWARD_CLASSIFIER
+}
+
+func (c *ForwardClassifier) Classes() []Class {
+	return []Class{
+		{Name: RED, Color: "red"},
+		{Name: MAGENTA, Color: "magenta"},
+		{Name: CYAN, Color: "cyan"},
+		{Name: YELLOW, Color: "yellow"},
+	}
+}
+
+func (c *ForwardClassifier) Fit(bugs []*Bug) {}
+
+func (c *ForwardClassifier) Reset() {}
+
+func (c *ForwardClassifier) Classify(b *Bug) string {
+	forwardMove := turnShares(b)[0] * 100
+	if forwardMove > 80 {
+		return YELLOW
+	} else if forwardMove > 50 {
+		return CYAN
+	} else if forwardMove > 25 {
+		return MAGENTA
+	}
+	return RED
+}
+
+const (
+	LEFT     = "Left"
+	BALANCED = "Balanced"
+	RIGHT    = "Right"
+)
+
+// TurnBiasClassifier buckets bugs by whether their turning leans to the
+// left or the right. Turns of less than half a revolution count as left,
+// since the headings of every geometry run anticlockwise on screen.
+type TurnBiasClassifier struct{}
+
+func (c *TurnBiasClassifier) Name() string {
+	return TURN_BIAS_CLASSIFIER
+}
+
+func (c *TurnBiasClassifier) Classes() []Class {
+	return []Class{
+		{Name: BALANCED, Color: "violet"},
+		{Name: LEFT, Color: "orange"},
+		{Name: RIGHT, Color: "deepskyblue"},
+	}
+}
+
+func (c *TurnBiasClassifier) Fit(bugs []*Bug) {}
+
+func (c *TurnBiasClassifier) Reset() {}
+
+func (c *TurnBiasClassifier) Classify(b *Bug) string {
+	shares := turnShares(b)
+	n := len(shares)
+	left, right := 0.0, 0.0
+	for i := 1; i < n; i++ {
+		if 2*i < n {
+			left += shares[i]
+		} else if 2*i > n {
+			right += shares[i]
+		}
+	}
+
+	bias := left - right
+	if bias > 0.2 {
+		return LEFT
+	} else if bias < -0.2 {
+		return RIGHT
+	}
+	return BALANCED
+}
+
+const (
+	SPECIALIST = "Specialist"
+	MIXED      = "Mixed"
+	GENERALIST = "Generalist"
+)
+
+// EntropyClassifier buckets bugs by the entropy of their turn chances, from
+// specialists that nearly always take one turn to generalists that take
+// every turn equally often.
+type EntropyClassifier struct{}
+
+func (c *EntropyClassifier) Name() string {
+	return ENTROPY_CLASSIFIER
+}
+
+func (c *EntropyClassifier) Classes() []Class {
+	return []Class{
+		{Name: GENERALIST, Color: "red"},
+		{Name: MIXED, Color: "orange"},
+		{Name: SPECIALIST, Color: "yellow"},
+	}
+}
+
+func (c *EntropyClassifier) Fit(bugs []*Bug) {}
+
+func (c *EntropyClassifier) Reset() {}
+
+func (c *EntropyClassifier) Classify(b *Bug) string {
+	shares := turnShares(b)
+
+	entropy := 0.0
+	for _, p := range shares {
+		if p > 0 {
+			entropy -= p * math.Log(p)
+		}
+	}
+	entropy /= math.Log(float64(len(shares)))
+
+	if entropy < 0.33 {
+		return SPECIALIST
+	} else if entropy < 0.66 {
+		return MIXED
+	}
+	return GENERALIST
+}
+
+var clusterColors = []string{"yellow", "cyan", "magenta", "orange", "deepskyblue", "violet", "red", "pink"}
+
+// KMeansClassifier clusters bugs by their chance of taking each turn. Each
+// fit starts from the previous centroids, so a cluster keeps its name and
+// color as the population drifts.
+type KMeansClassifier struct {
+	K int
+
+	centroids [][]float64
+}
+
+func NewKMeansClassifier(k int) *KMeansClassifier {
+	return &KMeansClassifier{K: k}
+}
+
+func (c *KMeansClassifier) Name() string {
+	return KMEANS_CLASSIFIER
+}
+
+func (c *KMeansClassifier) Classes() []Class {
+	result := make([]Class, c.K)
+	for i := range result {
+		result[i] = Class{Name: clusterName(i), Color: clusterColors[i%len(clusterColors)]}
+	}
+	return result
+}
+
+func clusterName(i int) string {
+	return fmt.Sprintf("Cluster %d", i+1)
+}
+
+// turnShares returns the chance a bug gives each turn where it stands, as
+// its brain decides, so bugs are compared on how they actually move whatever
+// steers them.
+func turnShares(b *Bug) []float64 {
+	return b.TurnProbabilities()
+}
+
+func (c *KMeansClassifier) Reset() {
+	c.centroids = nil
+}
+
+func (c *KMeansClassifier) Fit(bugs []*Bug) {
+	if len(bugs) == 0 {
+		return
+	}
+
+	points := make([][]float64, len(bugs))
+	for i, b := range bugs {
+		points[i] = turnShares(b)
+	}
+
+	if len(c.centroids) != c.K || len(c.centroids[0]) != len(points[0]) {
+		c.centroids = initialCentroids(points, c.K)
+	}
+
+	assignment := make([]int, len(points))
+	for range 20 {
+		changed := false
+		for i, p := range points {
+			nearest := c.nearest(p)
+			if nearest != assignment[i] {
+				assignment[i] = nearest
+				changed = true
+			}
+		}
+
+		sums := make([][]float64, c.K)
+		counts := make([]int, c.K)
+		for i, p := range points {
+			cluster := assignment[i]
+			if sums[cluster] == nil {
+				sums[cluster] = make([]float64, len(p))
+			}
+			for j, v := range p {
+				sums[cluster][j] += v
+			}
+			counts[cluster]++
+		}
+
+		// Empty clusters keep their centroid so they can pick up bugs later
+		for k := range c.centroids {
+			if counts[k] == 0 {
+				continue
+			}
+			for j := range c.centroids[k] {
+				c.centroids[k][j] = sums[k][j] / float64(counts[k])
+			}
+		}
+
+		if !changed {
+			break
+		}
+	}
+}
+
+// initialCentroids spreads the starting centroids across the population,
+// ordered by forward share, so that fitting never needs random numbers and
+// seeded runs stay reproducible.
+func initialCentroids(points [][]float64, k int) [][]float64 {
+	sorted := append([][]float64{}, points...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i][0] < sorted[j][0]
+	})
+
+	result := make([][]float64, k)
+	for i := range result {
+		p := sorted[(2*i+1)*len(sorted)/(2*k)]
+		result[i] = append([]float64{}, p...)
+	}
+	return result
+}
+
+func (c *KMeansClassifier) nearest(p []float64) int {
+	result := 0
+	best := math.MaxFloat64
+	for k, centroid := range c.centroids {
+		d := 0.0
+		for j := range p {
+			diff := p[j] - centroid[j]
+			d += diff * diff
+		}
+		if d < best {
+			result = k
+			best = d
+		}
+	}
+	return result
+}
+
+func (c *KMeansClassifier) Classify(b *Bug) string {
+	if len(c.centroids) == 0 || len(c.centroids[0]) != len(b.geneWeight) {
+		return clusterName(0)
+	}
+	return clusterName(c.nearest(turnShares(b)))
+}
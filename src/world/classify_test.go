@@ -0,0 +1,113 @@
+package world
+
+import (
+	"reflect"
+	"testing"
+)
+
+func bugWithGenes(genes ...int) *Bug {
+	b := &Bug{geneValue: genes}
+	b.updateWeights()
+	return b
+}
+
+func TestBuiltInClassifiers(t *testing.T) {
+	tests := []struct {
+		classifier string
+		genes      []int
+		expected   string
+	}{
+		{FORWARD_CLASSIFIER, []int{3, 0, 1, 0, 0, 0}, YELLOW},
+		{FORWARD_CLASSIFIER, []int{2, 1, 1, 0, 0, 0}, CYAN},
+		{FORWARD_CLASSIFIER, []int{1, 1, 1, 0, 0, 0}, MAGENTA},
+		{FORWARD_CLASSIFIER, []int{1, 2, 2, 2, 0, 0}, RED},
+		{TURN_BIAS_CLASSIFIER, []int{0, 2, 1, 0, 0, 0}, LEFT},
+		{TURN_BIAS_CLASSIFIER, []int{0, 0, 0, 0, 1, 2}, RIGHT},
+		{TURN_BIAS_CLASSIFIER, []int{0, 2, 0, 0, 0, 2}, BALANCED},
+		{TURN_BIAS_CLASSIFIER, []int{0, 2, 0, 0}, LEFT},
+		{TURN_BIAS_CLASSIFIER, []int{0, 0, 0, 2}, RIGHT},
+		{ENTROPY_CLASSIFIER, []int{5, 0, 0, 0, 0, 0}, SPECIALIST},
+		{ENTROPY_CLASSIFIER, []int{0, 1, 2, 3, 4, 0}, GENERALIST},
+		{ENTROPY_CLASSIFIER, []int{1, 1, 1, 1, 1, 1}, SPECIALIST},
+	}
+
+	for _, test := range tests {
+		c, err := ClassifierByName(test.classifier)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.classifier, err)
+		}
+		if class := c.Classify(bugWithGenes(test.genes...)); class != test.expected {
+			t.Errorf("%s %v: expected %s, got %s", test.classifier, test.genes, test.expected, class)
+		}
+	}
+}
+
+func TestKMeansClassifierSeparatesClusters(t *testing.T) {
+	bugs := []*Bug{}
+	for range 10 {
+		bugs = append(bugs, bugWithGenes(3, 0, 0, 0, 0, 0))
+		bugs = append(bugs, bugWithGenes(0, 0, 0, 3, 0, 0))
+	}
+
+	c := NewKMeansClassifier(2)
+	c.Fit(bugs)
+
+	forward := c.Classify(bugs[0])
+	reverse := c.Classify(bugs[1])
+	if forward == reverse {
+		t.Fatalf("expected two clusters, got %s for both", forward)
+	}
+
+	// Refitting keeps the cluster names
+	c.Fit(bugs[:6])
+	if c.Classify(bugs[0]) != forward || c.Classify(bugs[1]) != reverse {
+		t.Error("expected clusters to keep their names after refitting")
+	}
+}
+
+func TestInitializeResetsKMeansCentroids(t *testing.T) {
+	fresh := NewGameWorld(100, 100)
+	fresh.Seed = 7
+	fresh.SetClassifier(NewKMeansClassifier(4))
+	fresh.Initialize()
+
+	reused := NewGameWorld(100, 100)
+	reused.Seed = 3
+	reused.SetClassifier(NewKMeansClassifier(4))
+	reused.Initialize()
+	for range 500 {
+		reused.Next()
+	}
+	reused.Seed = 7
+	reused.Initialize()
+
+	a := fresh.Classifier().(*KMeansClassifier).centroids
+	b := reused.Classifier().(*KMeansClassifier).centroids
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("expected a new run to fit its centroids from scratch, got %v and %v", b, a)
+	}
+}
+
+func TestHistoryTracksClassifierClasses(t *testing.T) {
+	w := NewGameWorld(100, 100)
+	w.Initialize()
+	w.SetClassifier(&EntropyClassifier{})
+	w.addHistoryEntry()
+
+	entry := w.History()[0]
+	if len(entry.Classes) != 3 {
+		t.Fatalf("expected 3 classes, got %v", entry.Classes)
+	}
+
+	total := 0
+	for _, class := range w.Classifier().Classes() {
+		count, ok := entry.Classes[class.Name]
+		if !ok {
+			t.Errorf("expected a count for %s", class.Name)
+		}
+		total += count
+	}
+	if total != entry.BugCount {
+		t.Errorf("expected class counts to add up to %d, got %d", entry.BugCount, total)
+	}
+}
@@ -0,0 +1,151 @@
+package world
+
+import (
+	"fmt"
+	"slices"
+)
+
+type FeedingOrder int
+
+const (
+	SLICE_ORDER  FeedingOrder = iota // bugs move and feed in the order they are stored, the original behaviour
+	RANDOM_ORDER                     // the order is shuffled every cycle
+	ENERGY_ORDER                     // bugs with the most energy go first
+)
+
+// Competition decides how bugs interfere with each other. The zero value
+// lets bugs overlap freely and feed in the order they are stored.
+type Competition struct {
+	FeedingOrder FeedingOrder `json:"feedingOrder"`
+	Exclusion    bool         `json:"exclusion,omitempty"` // a bug cannot move onto a cell held by another bug
+	StealPercent int          `json:"stealPercent"`        // energy taken from each loser of a contest, 0 for no contests
+}
+
+// ParseFeedingOrder reads a feeding order written as "slice", "random" or
+// "energy".
+func ParseFeedingOrder(s string) (FeedingOrder, error) {
+	for _, o := range []FeedingOrder{SLICE_ORDER, RANDOM_ORDER, ENERGY_ORDER} {
+		if o.String() == s {
+			return o, nil
+		}
+	}
+	return SLICE_ORDER, fmt.Errorf("unknown feeding order %q", s)
+}
+
+func (o FeedingOrder) String() string {
+	switch o {
+	case RANDOM_ORDER:
+		return "random"
+	case ENERGY_ORDER:
+		return "energy"
+	}
+	return "slice"
+}
+
+func (c Competition) Validate() error {
+	if c.FeedingOrder < SLICE_ORDER || c.FeedingOrder > ENERGY_ORDER {
+		return fmt.Errorf("unknown feeding order %d", c.FeedingOrder)
+	}
+	if c.StealPercent < 0 || c.StealPercent > 100 {
+		return fmt.Errorf("steal percentage must be between 0 and 100")
+	}
+	return nil
+}
+
+// feedingOrder returns the bugs in the order they move and feed this cycle.
+func (w *GameWorld) feedingOrder(bugs []*Bug) []*Bug {
+	switch w.Competition.FeedingOrder {
+	case RANDOM_ORDER:
+		bugs = slices.Clone(bugs)
+		w.rng.Shuffle(len(bugs), func(i, j int) { bugs[i], bugs[j] = bugs[j], bugs[i] })
+	case ENERGY_ORDER:
+		bugs = slices.Clone(bugs)
+		slices.SortStableFunc(bugs, func(a, b *Bug) int { return b.Energy - a.Energy })
+	}
+	return bugs
+}
+
+// occupancy counts the bugs on each cell, for Competition.Exclusion.
+type occupancy map[int]int
+
+func (w *GameWorld) newOccupancy(bugs []*Bug) occupancy {
+	result := occupancy{}
+	for _, b := range bugs {
+		result[b.Y*w.Width+b.X]++
+	}
+	return result
+}
+
+// moveBug moves a bug, keeping it out of walls, and off cells held by other
+// bugs when Competition.Exclusion is on. It reports false when the bug falls
+// off an absorbing edge of the world.
+func (w *GameWorld) moveBug(b *Bug, occupied occupancy) bool {
+	if !w.Competition.Exclusion {
+		return b.Update(w.rng, w.geometry, w.Width, w.Height, w.Boundary, w.walls(b))
+	}
+
+	from := b.Y*w.Width + b.X
+	walls := w.walls(b)
+	moved := b.Update(w.rng, w.geometry, w.Width, w.Height, w.Boundary, func(x, y int) bool {
+		return (walls != nil && walls(x, y)) || occupied[y*w.Width+x] > 0
+	})
+	if to := b.Y*w.Width + b.X; to != from {
+		occupied[from]--
+		occupied[to]++
+	}
+	return moved
+}
+
+// contest makes the bugs sharing a cell fight over their energy. On each
+// shared cell one bug wins, with a chance in proportion to its energy, and
+// takes Competition.StealPercent of the energy of every other bug there.
+func (w *GameWorld) contest(bugs []*Bug) {
+	if w.Competition.StealPercent == 0 {
+		return
+	}
+
+	cells := map[int][]*Bug{}
+	positions := []int{}
+	for _, b := range bugs {
+		pos := b.Y*w.Width + b.X
+		if _, ok := cells[pos]; !ok {
+			positions = append(positions, pos)
+		}
+		cells[pos] = append(cells[pos], b)
+	}
+
+	for _, pos := range positions {
+		rivals := cells[pos]
+		if len(rivals) < 2 {
+			continue
+		}
+
+		total := 0
+		for _, b := range rivals {
+			total += max(0, b.Energy)
+		}
+		if total == 0 {
+			continue
+		}
+
+		n := w.rng.IntN(total)
+		winner := rivals[len(rivals)-1]
+		for _, b := range rivals {
+			if n < max(0, b.Energy) {
+				winner = b
+				break
+			}
+			n -= max(0, b.Energy)
+		}
+
+		for _, b := range rivals {
+			if b == winner || b.Energy <= 0 {
+				continue
+			}
+			stolen := b.Energy * w.Competition.StealPercent / 100
+			b.Energy -= stolen
+			winner.Energy += stolen
+		}
+		winner.Energy = min(winner.Energy, w.Rules.MaxEnergy)
+	}
+}
@@ -0,0 +1,86 @@
+package world
+
+import (
+	"testing"
+)
+
+// forwardBug returns a bug that always keeps moving in direction 0.
+func forwardBug(x, y, energy int) *Bug {
+	b := bugWithGenes(2, 0, 0, 0, 0, 0)
+	b.X, b.Y, b.Energy = x, y, energy
+	return b
+}
+
+func TestFeedingOrder(t *testing.T) {
+	tests := []struct {
+		order    FeedingOrder
+		expected int // index of the bug expected to eat the only bacterium
+	}{
+		{SLICE_ORDER, 0},
+		{ENERGY_ORDER, 1},
+	}
+
+	for _, test := range tests {
+		w := NewGameWorld(20, 20)
+		w.InitialBacteria = 0
+		w.InitialBugCount = 0
+		w.ReseedBacteria = 0
+		w.Rules.FeedingRadius = 0
+		w.Competition.FeedingOrder = test.order
+		w.Initialize()
+
+		offset := w.ActiveGeometry().Directions[0]
+		w.growFood((10+offset.Y)*w.Width+10+offset.X, 1)
+		w.bugs = []*Bug{forwardBug(10, 10, 100), forwardBug(10, 10, 200)}
+		w.Next()
+
+		for i, b := range w.bugs {
+			fed := b.Energy > [2]int{100, 200}[i]
+			if fed != (i == test.expected) {
+				t.Errorf("%s order: expected bug %d to eat, got energies %d and %d", test.order, test.expected, w.bugs[0].Energy, w.bugs[1].Energy)
+				break
+			}
+		}
+	}
+}
+
+func TestExclusionBlocksOccupiedCells(t *testing.T) {
+	w := NewGameWorld(20, 20)
+	w.InitialBugCount = 0
+	w.Competition.Exclusion = true
+	w.Initialize()
+
+	offset := w.ActiveGeometry().Directions[0]
+	behind := forwardBug(10, 10, 100)
+	ahead := forwardBug(10+offset.X, 10+offset.Y, 100)
+	w.bugs = []*Bug{behind, ahead}
+	w.Next()
+
+	if behind.X != 10 || behind.Y != 10 {
+		t.Errorf("expected the bug behind to be blocked, it moved to %d, %d", behind.X, behind.Y)
+	}
+	if ahead.X != 10+2*offset.X || ahead.Y != 10+2*offset.Y {
+		t.Errorf("expected the bug ahead to move on, it is at %d, %d", ahead.X, ahead.Y)
+	}
+}
+
+func TestContestStealsEnergy(t *testing.T) {
+	w := NewGameWorld(20, 20)
+	w.InitialBugCount = 0
+	w.Competition.StealPercent = 50
+	w.Initialize()
+
+	for range 20 {
+		strong := forwardBug(5, 5, 300)
+		weak := forwardBug(5, 5, 100)
+		alone := forwardBug(8, 8, 100)
+		w.contest([]*Bug{strong, weak, alone})
+
+		if !(strong.Energy == 350 && weak.Energy == 50) && !(strong.Energy == 150 && weak.Energy == 250) {
+			t.Fatalf("expected one bug to steal half the other's energy, got %d and %d", strong.Energy, weak.Energy)
+		}
+		if alone.Energy != 100 {
+			t.Fatalf("expected a bug on its own cell to keep its energy, got %d", alone.Energy)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package world
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HistoryClassNames returns every class counted anywhere in the history,
+// sorted by name. The classes can change part way through a run when the
+// classifier is switched.
+func HistoryClassNames(history []HistoryEntry) []string {
+	return historyNames(history, func(h HistoryEntry) map[string]int { return h.Classes })
+}
+
+// HistoryEnvironmentNames returns every scheduled parameter recorded
+// anywhere in the history, sorted by name.
+func HistoryEnvironmentNames(history []HistoryEntry) []string {
+	return historyNames(history, func(h HistoryEntry) map[string]int { return h.Environment })
+}
+
+// HistoryFoodNames returns every kind of food counted anywhere in the
+// history, sorted by name.
+func HistoryFoodNames(history []HistoryEntry) []string {
+	return historyNames(history, func(h HistoryEntry) map[string]int { return h.Food })
+}
+
+func historyNames(history []HistoryEntry, counts func(h HistoryEntry) map[string]int) []string {
+	seen := map[string]bool{}
+	for _, h := range history {
+		for name := range counts(h) {
+			seen[name] = true
+		}
+	}
+
+	result := make([]string, 0, len(seen))
+	for name := range seen {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// WriteHistoryCSV writes the history entries as CSV, one row per entry,
+// preceded by a header row. There is one column for each kind of food and
+// each class in the history, holding 0 for entries that did not count it,
+// followed by the mutation policy in force and the value of each scheduled
+// parameter.
+func WriteHistoryCSV(out io.Writer, history []HistoryEntry) error {
+	foods := HistoryFoodNames(history)
+	classes := HistoryClassNames(history)
+	environment := HistoryEnvironmentNames(history)
+
+	header := []string{"cycle", "bacteria_count", "bacteria_percent", "bug_count", "predator_count"}
+	for _, name := range foods {
+		header = append(header, columnName(name)+"_food")
+	}
+	for _, name := range classes {
+		header = append(header, columnName(name)+"_bugs")
+	}
+	header = append(header, "mutation")
+	for _, name := range environment {
+		header = append(header, columnName(name))
+	}
+
+	writer := csv.NewWriter(out)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, h := range history {
+		record := []string{
+			strconv.Itoa(h.Cycle),
+			strconv.Itoa(h.BacteriaCount),
+			strconv.FormatFloat(h.BacteriaPercent, 'f', -1, 64),
+			strconv.Itoa(h.BugCount),
+			strconv.Itoa(h.PredatorCount),
+		}
+		for _, name := range foods {
+			record = append(record, strconv.Itoa(h.Food[name]))
+		}
+		for _, name := range classes {
+			record = append(record, strconv.Itoa(h.Classes[name]))
+		}
+		record = append(record, h.Mutation)
+		for _, name := range environment {
+			record = append(record, strconv.Itoa(h.Environment[name]))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func columnName(name string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "_", "-", "_").Replace(name))
+}
+
+// WriteHistoryJSON writes the history entries as a JSON array.
+func WriteHistoryJSON(out io.Writer, history []HistoryEntry) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(history)
+}
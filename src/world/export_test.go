@@ -0,0 +1,62 @@
+package world
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+var exportHistory = []HistoryEntry{
+	{Cycle: 20, BacteriaCount: 300, BacteriaPercent: 0.03, BugCount: 20,
+		Food:     map[string]int{BACTERIA: 300},
+		Classes:  map[string]int{YELLOW: 1, CYAN: 2, MAGENTA: 3, RED: 14},
+		Mutation: "one gene; step 1"},
+	{Cycle: 40, BacteriaCount: 250, BacteriaPercent: 0.025, BugCount: 19, PredatorCount: 3,
+		Food:        map[string]int{BACTERIA: 200, "toxin": 50},
+		Classes:     map[string]int{RED: 19, "Cluster 1": 0},
+		Mutation:    "off",
+		Environment: map[string]int{"reseed": 30}},
+}
+
+func TestWriteHistoryCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteHistoryCSV(&buf, exportHistory); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "cycle,bacteria_count,bacteria_percent,bug_count,predator_count,bacteria_food,toxin_food,cluster_1_bugs,cyan_bugs,magenta_bugs,red_bugs,yellow_bugs,mutation,reseed\n" +
+		"20,300,0.03,20,0,300,0,0,2,3,14,1,one gene; step 1,0\n" +
+		"40,250,0.025,19,3,200,50,0,0,0,19,0,off,30\n"
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestWriteHistoryJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteHistoryJSON(&buf, exportHistory); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var decoded []HistoryEntry
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, exportHistory) {
+		t.Errorf("expected %+v, got %+v", exportHistory, decoded)
+	}
+}
+
+func TestKeepFullHistory(t *testing.T) {
+	w := NewGameWorld(10, 10)
+	w.KeepFullHistory = true
+	w.Initialize()
+	for range 20 {
+		w.addHistoryEntry()
+	}
+
+	if len(w.History()) != 20 {
+		t.Errorf("expected 20 history entries, got %d", len(w.History()))
+	}
+}
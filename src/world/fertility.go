@@ -0,0 +1,162 @@
+package world
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type RegionShape int
+
+const (
+	RECTANGLE RegionShape = iota
+	CIRCLE
+)
+
+// FertilityRegion is an area of the world where bacteria regrow at their own
+// rate, on top of the uniform reseeding of the whole world. It is Dewdney's
+// "Garden of Eden" from the original Palmiter experiment.
+type FertilityRegion struct {
+	Shape  RegionShape `json:"shape"`
+	X      int         `json:"x"`      // left edge of a rectangle, or center of a circle
+	Y      int         `json:"y"`      // top edge of a rectangle, or center of a circle
+	Width  int         `json:"width"`  // rectangle only
+	Height int         `json:"height"` // rectangle only
+	Radius int         `json:"radius"` // circle only
+
+	ReseedBacteria int `json:"reseedBacteria"` // same units as GameWorld.ReseedBacteria
+
+	reseedTotal int
+}
+
+// ParseFertilityRegion reads a region written as either
+// "rect X Y WIDTH HEIGHT RATE" or "circle X Y RADIUS RATE".
+func ParseFertilityRegion(s string) (*FertilityRegion, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty fertility region")
+	}
+
+	values := make([]int, 0, len(fields)-1)
+	for _, f := range fields[1:] {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q in fertility region %q", f, s)
+		}
+		values = append(values, n)
+	}
+
+	var result *FertilityRegion
+	switch strings.ToLower(fields[0]) {
+	case "rect", "rectangle":
+		if len(values) != 5 {
+			return nil, fmt.Errorf("rectangle region %q needs X Y WIDTH HEIGHT RATE", s)
+		}
+		result = &FertilityRegion{
+			Shape:          RECTANGLE,
+			X:              values[0],
+			Y:              values[1],
+			Width:          values[2],
+			Height:         values[3],
+			ReseedBacteria: values[4],
+		}
+	case "circle":
+		if len(values) != 4 {
+			return nil, fmt.Errorf("circle region %q needs X Y RADIUS RATE", s)
+		}
+		result = &FertilityRegion{
+			Shape:          CIRCLE,
+			X:              values[0],
+			Y:              values[1],
+			Radius:         values[2],
+			ReseedBacteria: values[3],
+		}
+	default:
+		return nil, fmt.Errorf("unknown fertility region shape %q", fields[0])
+	}
+
+	if err := result.Validate(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ParseFertilityRegions reads one region per line, ignoring blank lines.
+func ParseFertilityRegions(s string) ([]*FertilityRegion, error) {
+	result := []*FertilityRegion{}
+	for _, line := range strings.Split(s, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		region, err := ParseFertilityRegion(line)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, region)
+	}
+	return result, nil
+}
+
+func (r *FertilityRegion) String() string {
+	if r.Shape == CIRCLE {
+		return fmt.Sprintf("circle %d %d %d %d", r.X, r.Y, r.Radius, r.ReseedBacteria)
+	}
+	return fmt.Sprintf("rect %d %d %d %d %d", r.X, r.Y, r.Width, r.Height, r.ReseedBacteria)
+}
+
+func (r *FertilityRegion) Validate() error {
+	if r.ReseedBacteria < 0 {
+		return fmt.Errorf("fertility region %s has a negative reseed rate", r)
+	}
+	if r.Shape == CIRCLE && r.Radius <= 0 {
+		return fmt.Errorf("fertility region %s needs a positive radius", r)
+	}
+	if r.Shape == RECTANGLE && (r.Width <= 0 || r.Height <= 0) {
+		return fmt.Errorf("fertility region %s needs a positive width and height", r)
+	}
+	return nil
+}
+
+func (r *FertilityRegion) Contains(x, y int) bool {
+	if r.Shape == CIRCLE {
+		dx := x - r.X
+		dy := y - r.Y
+		return dx*dx+dy*dy <= r.Radius*r.Radius
+	}
+	return x >= r.X && x < r.X+r.Width && y >= r.Y && y < r.Y+r.Height
+}
+
+// randomPoint picks a random point inside the region, which may lie outside
+// the world if the region overlaps its edges.
+func (r *FertilityRegion) randomPoint(w *GameWorld) (int, int) {
+	if r.Shape == CIRCLE {
+		for {
+			x := r.X - r.Radius + w.rng.IntN(2*r.Radius+1)
+			y := r.Y - r.Radius + w.rng.IntN(2*r.Radius+1)
+			if r.Contains(x, y) {
+				return x, y
+			}
+		}
+	}
+	return r.X + w.rng.IntN(r.Width), r.Y + w.rng.IntN(r.Height)
+}
+
+// reseedRegion grows new bacteria inside a fertility region. A full region
+// is left alone rather than searched forever for an empty cell.
+func (w *GameWorld) reseedRegion(r *FertilityRegion) {
+	for r.reseedTotal >= 0 {
+		r.reseedTotal -= 100
+		for range 100 {
+			x, y := r.randomPoint(w)
+			if x < 0 || y < 0 || x >= w.Width || y >= w.Height {
+				continue
+			}
+			if w.cells[y*w.Width+x] == 0 && !w.isWall(x, y) {
+				w.growFood(y*w.Width+x, 1)
+				break
+			}
+		}
+	}
+
+	r.reseedTotal += r.ReseedBacteria
+}
@@ -0,0 +1,65 @@
+package world
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// FertilityMap scales how readily bacteria regrow in each part of the world.
+// A value of 255 regrows at the full reseed rate and 0 never regrows. The map
+// is stretched over the world, so it does not need to be the same size.
+type FertilityMap struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Values []byte `json:"values"`
+}
+
+// NewFertilityMap builds a fertility map from the brightness of an image.
+func NewFertilityMap(img image.Image) *FertilityMap {
+	bounds := img.Bounds()
+	result := &FertilityMap{
+		Width:  bounds.Dx(),
+		Height: bounds.Dy(),
+		Values: make([]byte, bounds.Dx()*bounds.Dy()),
+	}
+
+	for y := range result.Height {
+		for x := range result.Width {
+			gray := color.GrayModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray)
+			result.Values[y*result.Width+x] = gray.Y
+		}
+	}
+
+	return result
+}
+
+// LoadFertilityMap reads a fertility map from a PNG image.
+func LoadFertilityMap(r io.Reader) (*FertilityMap, error) {
+	img, err := png.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFertilityMap(img), nil
+}
+
+func (m *FertilityMap) Validate() error {
+	if m.Width <= 0 || m.Height <= 0 {
+		return fmt.Errorf("invalid fertility map size %d x %d", m.Width, m.Height)
+	}
+	if len(m.Values) != m.Width*m.Height {
+		return fmt.Errorf("fertility map has %d values, expected %d", len(m.Values), m.Width*m.Height)
+	}
+	return nil
+}
+
+// At returns the fertility of the world cell x, y for a world of the given
+// size.
+func (m *FertilityMap) At(x, y, worldWidth, worldHeight int) byte {
+	mx := x * m.Width / worldWidth
+	my := y * m.Height / worldHeight
+	return m.Values[my*m.Width+mx]
+}
@@ -0,0 +1,155 @@
+package world
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestParseFertilityRegion(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected FertilityRegion
+	}{
+		{"rect 10 20 30 40 300", FertilityRegion{Shape: RECTANGLE, X: 10, Y: 20, Width: 30, Height: 40, ReseedBacteria: 300}},
+		{"  circle 50 60 25 150 ", FertilityRegion{Shape: CIRCLE, X: 50, Y: 60, Radius: 25, ReseedBacteria: 150}},
+	}
+
+	for _, test := range tests {
+		region, err := ParseFertilityRegion(test.input)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", test.input, err)
+			continue
+		}
+		if *region != test.expected {
+			t.Errorf("%q: expected %+v, got %+v", test.input, test.expected, *region)
+		}
+		if reparsed, _ := ParseFertilityRegion(region.String()); reparsed == nil || *reparsed != *region {
+			t.Errorf("%q: String() did not round trip, got %q", test.input, region.String())
+		}
+	}
+}
+
+func TestParseFertilityRegionErrors(t *testing.T) {
+	for _, input := range []string{"", "square 1 2 3", "rect 1 2 3 4", "circle 1 2 x 4", "circle 1 2 0 4", "rect 1 2 3 4 -5"} {
+		if _, err := ParseFertilityRegion(input); err == nil {
+			t.Errorf("%q: expected an error", input)
+		}
+	}
+}
+
+func TestFertilityRegionReseedsInsideRegion(t *testing.T) {
+	w := NewGameWorld(50, 50)
+	w.InitialBacteria = 0
+	w.InitialBugCount = 0
+	w.ReseedBacteria = 0
+	region := &FertilityRegion{Shape: CIRCLE, X: 25, Y: 25, Radius: 5, ReseedBacteria: 100}
+	w.FertilityRegions = []*FertilityRegion{region}
+	w.Initialize()
+
+	for range 50 {
+		w.Next()
+	}
+
+	inside, outside := 0, 0
+	for x := range w.Width {
+		for y := range w.Height {
+			if v, _ := w.GetCell(x, y); v != 0 {
+				if region.Contains(x, y) {
+					inside++
+				} else {
+					outside++
+				}
+			}
+		}
+	}
+
+	// The uniform reseed places a single bacterium on the first cycle
+	if outside > 1 {
+		t.Errorf("expected at most 1 bacterium outside the region, got %d", outside)
+	}
+	if inside < 50 {
+		t.Errorf("expected at least 50 bacteria inside the region, got %d", inside)
+	}
+}
+
+func TestFertilityMapReseedsOnlyFertileCells(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 2, 1))
+	img.SetGray(0, 0, color.Gray{Y: 0})
+	img.SetGray(1, 0, color.Gray{Y: 255})
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("unable to encode image: %v", err)
+	}
+	fertilityMap, err := LoadFertilityMap(&buf)
+	if err != nil {
+		t.Fatalf("unable to load fertility map: %v", err)
+	}
+
+	w := NewGameWorld(40, 40)
+	w.InitialBacteria = 0
+	w.InitialBugCount = 0
+	w.ReseedBacteria = 300
+	w.FertilityMap = fertilityMap
+	w.Initialize()
+
+	for range 200 {
+		w.Next()
+	}
+
+	left, right := 0, 0
+	for x := range w.Width {
+		for y := range w.Height {
+			if v, _ := w.GetCell(x, y); v != 0 {
+				if x < w.Width/2 {
+					left++
+				} else {
+					right++
+				}
+			}
+		}
+	}
+
+	if left != 0 {
+		t.Errorf("expected no bacteria on the barren half, got %d", left)
+	}
+	if right == 0 {
+		t.Error("expected bacteria on the fertile half")
+	}
+}
+
+func TestWhiteFertilityMapReseedsLikeNoMap(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 1, 1))
+	img.SetGray(0, 0, color.Gray{Y: 255})
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("unable to encode image: %v", err)
+	}
+	fertilityMap, err := LoadFertilityMap(&buf)
+	if err != nil {
+		t.Fatalf("unable to load fertility map: %v", err)
+	}
+
+	w := NewGameWorld(20, 20)
+	w.InitialBacteria = 0
+	w.InitialBugCount = 0
+	w.FertilityMap = fertilityMap
+	w.Initialize()
+
+	// With nearly every cell taken, each bacterium must still find one of
+	// the few empty cells left.
+	for i := 10; i < len(w.cells); i++ {
+		w.growFood(i, 1)
+	}
+	before := w.BacteriaCount()
+	w.reseedTotal = 499
+	w.reseed()
+
+	if grown := w.BacteriaCount() - before; grown != 5 {
+		t.Errorf("expected 5 bacteria to grow, got %d", grown)
+	}
+}
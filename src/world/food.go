@@ -0,0 +1,237 @@
+package world
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BACTERIA is the name of the original food, stored as 1 in a cell. It
+// grows at GameWorld.ReseedBacteria and gives Rules.EnergyPerBacterium.
+const BACTERIA = "Bacteria"
+
+// FoodType is a kind of food growing alongside the original bacteria, with
+// its own colour, regrowth rate and energy. Food types are stored in cells
+// as 2 onwards, in the order of GameWorld.FoodTypes.
+type FoodType struct {
+	Name            string `json:"name"`
+	Color           string `json:"color"`
+	Energy          int    `json:"energy"`          // energy gained for each cell eaten, negative for toxic food
+	InitialBacteria int    `json:"initialBacteria"` // same units as GameWorld.InitialBacteria
+	ReseedBacteria  int    `json:"reseedBacteria"`  // same units as GameWorld.ReseedBacteria
+
+	reseedTotal int
+}
+
+// ParseFoodType reads a food type written as
+// "NAME COLOR ENERGY INITIAL RATE", e.g. "toxin purple -60 1 20".
+func ParseFoodType(s string) (*FoodType, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("food type %q needs NAME COLOR ENERGY INITIAL RATE", s)
+	}
+
+	values := make([]int, 0, 3)
+	for _, f := range fields[2:] {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q in food type %q", f, s)
+		}
+		values = append(values, n)
+	}
+
+	result := &FoodType{
+		Name:            fields[0],
+		Color:           fields[1],
+		Energy:          values[0],
+		InitialBacteria: values[1],
+		ReseedBacteria:  values[2],
+	}
+	if err := result.Validate(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ParseFoodTypes reads one food type per line, ignoring blank lines.
+func ParseFoodTypes(s string) ([]*FoodType, error) {
+	result := []*FoodType{}
+	for _, line := range strings.Split(s, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		food, err := ParseFoodType(line)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, food)
+	}
+	if err := ValidateFoodTypes(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (f *FoodType) String() string {
+	return fmt.Sprintf("%s %s %d %d %d", f.Name, f.Color, f.Energy, f.InitialBacteria, f.ReseedBacteria)
+}
+
+func (f *FoodType) Validate() error {
+	if f.Name == "" || strings.ContainsAny(f.Name, " \t\n") {
+		return fmt.Errorf("food type name %q must be a single word", f.Name)
+	}
+	if strings.EqualFold(f.Name, BACTERIA) {
+		return fmt.Errorf("food type name %q is taken by the original bacteria", f.Name)
+	}
+	if f.Color == "" {
+		return fmt.Errorf("food type %s needs a colour", f.Name)
+	}
+	if f.InitialBacteria < 0 || f.InitialBacteria > 100 {
+		return fmt.Errorf("food type %s needs a starting percentage between 0 and 100", f.Name)
+	}
+	if f.ReseedBacteria < 0 {
+		return fmt.Errorf("food type %s has a negative reseed rate", f.Name)
+	}
+	return nil
+}
+
+// ValidateFoodTypes checks each food type, and that there are few enough
+// to fit in a cell and no two share a name.
+func ValidateFoodTypes(foods []*FoodType) error {
+	if len(foods) > 254 {
+		return fmt.Errorf("too many food types, at most 254 are allowed")
+	}
+
+	seen := map[string]bool{}
+	for _, f := range foods {
+		if err := f.Validate(); err != nil {
+			return err
+		}
+		if seen[strings.ToLower(f.Name)] {
+			return fmt.Errorf("food type %s is listed twice", f.Name)
+		}
+		seen[strings.ToLower(f.Name)] = true
+	}
+	return nil
+}
+
+// FoodNames returns the name of every kind of food, starting with the
+// original bacteria. The index of a name is the food preference of a bug
+// preferring it, and one less than the value of a cell holding it.
+func (w *GameWorld) FoodNames() []string {
+	result := []string{BACTERIA}
+	for _, f := range w.FoodTypes {
+		result = append(result, f.Name)
+	}
+	return result
+}
+
+// FoodColor returns the colour to draw a cell holding the given value in.
+func (w *GameWorld) FoodColor(value byte) string {
+	if value >= 2 && int(value)-2 < len(w.FoodTypes) {
+		return w.FoodTypes[value-2].Color
+	}
+	return "green"
+}
+
+// foodEnergy returns the energy a bug gains from eating a cell holding the
+// given value. With FoodPreferences on, a bug only gets half the energy of
+// food it does not prefer, while toxic food always drains it in full.
+func (w *GameWorld) foodEnergy(b *Bug, value byte) int {
+	energy := w.scheduled(BACTERIUM_ENERGY_PARAM, w.Rules.EnergyPerBacterium)
+	if value >= 2 && int(value)-2 < len(w.FoodTypes) {
+		energy = w.FoodTypes[value-2].Energy
+	}
+
+	if w.FoodPreferences && energy > 0 && b.FoodPreference != int(value)-1 {
+		energy /= 2
+	}
+	return energy
+}
+
+// countFood returns how many cells hold each kind of food.
+func (w *GameWorld) countFood() map[string]int {
+	counts := make([]int, len(w.FoodTypes)+2)
+	for _, v := range w.cells {
+		if int(v) < len(counts) {
+			counts[v]++
+		}
+	}
+
+	result := map[string]int{}
+	for i, name := range w.FoodNames() {
+		result[name] = counts[i+1]
+	}
+	return result
+}
+
+// growFood puts food of the given value on the empty cell at pos.
+func (w *GameWorld) growFood(pos int, value byte) {
+	w.cells[pos] = value
+	w.foodCount++
+	if value == 1 {
+		w.bacteriaCount++
+	}
+}
+
+// clearFood takes any food off the cell at pos and returns its value.
+func (w *GameWorld) clearFood(pos int) byte {
+	v := w.cells[pos]
+	if v != 0 {
+		w.cells[pos] = 0
+		w.foodCount--
+		if v == 1 {
+			w.bacteriaCount--
+		}
+	}
+	return v
+}
+
+// recountFood counts the food on the cells from scratch.
+func (w *GameWorld) recountFood() {
+	w.bacteriaCount = 0
+	w.foodCount = 0
+	for _, v := range w.cells {
+		if v != 0 {
+			w.foodCount++
+		}
+		if v == 1 {
+			w.bacteriaCount++
+		}
+	}
+}
+
+// seedFood scatters a food type over the empty cells of a new world.
+func (w *GameWorld) seedFood(f *FoodType, value byte) {
+	for i := range w.cells {
+		if w.cells[i] == 0 && w.rng.IntN(100) < f.InitialBacteria && !w.isWall(i%w.Width, i/w.Width) {
+			w.growFood(i, value)
+		}
+	}
+}
+
+// reseedFood grows a food type anywhere in the world. Like reseedRegion, it
+// gives up after 100 attempts to find an empty cell.
+func (w *GameWorld) reseedFood(f *FoodType, value byte) {
+	for f.reseedTotal >= 0 {
+		f.reseedTotal -= 100
+		for range 100 {
+			x := w.rng.IntN(w.Width)
+			y := w.rng.IntN(w.Height)
+			if w.cells[y*w.Width+x] == 0 && !w.isWall(x, y) {
+				w.growFood(y*w.Width+x, value)
+				break
+			}
+		}
+	}
+
+	f.reseedTotal += f.ReseedBacteria
+}
+
+// mutateFoodPreference occasionally switches the food preference of a child
+// to a random food, as the mutation policy decides.
+func (w *GameWorld) mutateFoodPreference(b *Bug) {
+	if w.Mutation.mutatesPreference(w.rng, b) {
+		b.FoodPreference = w.rng.IntN(len(w.FoodTypes) + 1)
+	}
+}
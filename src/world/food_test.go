@@ -0,0 +1,98 @@
+package world
+
+import (
+	"testing"
+)
+
+func TestParseFoodType(t *testing.T) {
+	food, err := ParseFoodType("  toxin purple -60 1 20 ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := FoodType{Name: "toxin", Color: "purple", Energy: -60, InitialBacteria: 1, ReseedBacteria: 20}
+	if *food != expected {
+		t.Errorf("expected %+v, got %+v", expected, *food)
+	}
+	if reparsed, _ := ParseFoodType(food.String()); reparsed == nil || *reparsed != *food {
+		t.Errorf("String() did not round trip, got %q", food.String())
+	}
+
+	for _, input := range []string{"", "toxin purple -60 1", "toxin purple x 1 20", "bacteria green 40 1 20", "toxin purple -60 101 20", "toxin purple -60 1 -20"} {
+		if _, err := ParseFoodType(input); err == nil {
+			t.Errorf("%q: expected an error", input)
+		}
+	}
+
+	if _, err := ParseFoodTypes("seed yellow 80 1 10\n\nSeed orange 20 1 10"); err == nil {
+		t.Error("expected an error for a food type listed twice")
+	}
+}
+
+func TestFoodEnergy(t *testing.T) {
+	w := NewGameWorld(10, 10)
+	w.Rules.EnergyPerBacterium = 40
+	w.FoodTypes = []*FoodType{
+		{Name: "seed", Color: "yellow", Energy: 80},
+		{Name: "toxin", Color: "purple", Energy: -60},
+	}
+
+	b := &Bug{FoodPreference: 1}
+	tests := []struct {
+		preferences bool
+		value       byte
+		expected    int
+	}{
+		{false, 1, 40},
+		{false, 2, 80},
+		{false, 3, -60},
+		{true, 1, 20},
+		{true, 2, 80},
+		{true, 3, -60},
+	}
+	for _, test := range tests {
+		w.FoodPreferences = test.preferences
+		if energy := w.foodEnergy(b, test.value); energy != test.expected {
+			t.Errorf("preferences %v, food %d: expected %d energy, got %d", test.preferences, test.value, test.expected, energy)
+		}
+	}
+}
+
+func TestFoodTypesGrowAndAreCounted(t *testing.T) {
+	w := NewGameWorld(50, 50)
+	w.Seed = 1
+	w.InitialBugCount = 0
+	w.ReseedBacteria = 0
+	w.FoodTypes = []*FoodType{{Name: "toxin", Color: "purple", Energy: -60, InitialBacteria: 10, ReseedBacteria: 500}}
+	w.Initialize()
+	w.Rules.HistoryInterval = 1
+	w.Next()
+	w.Next()
+
+	history := w.History()
+	entry := history[len(history)-1]
+	if entry.Food["toxin"] <= 0 || entry.Food[BACTERIA] != entry.BacteriaCount {
+		t.Errorf("expected toxin to be counted apart from the bacteria, got %v for %d bacteria", entry.Food, entry.BacteriaCount)
+	}
+	if food := w.countFood(); w.FoodCount() != food[BACTERIA]+food["toxin"] || w.BacteriaCount() != food[BACTERIA] {
+		t.Errorf("expected %v cells of food, got %d in all and %d bacteria", food, w.FoodCount(), w.BacteriaCount())
+	}
+	if w.FoodColor(2) != "purple" || w.FoodColor(1) != "green" {
+		t.Errorf("unexpected food colours %q and %q", w.FoodColor(2), w.FoodColor(1))
+	}
+
+	data, err := w.MarshalSnapshot()
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	restored := NewGameWorld(10, 10)
+	if err := restored.UnmarshalSnapshot(data); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if len(restored.FoodTypes) != 1 || restored.FoodTypes[0].reseedTotal != w.FoodTypes[0].reseedTotal {
+		t.Errorf("expected the food types to be restored, got %+v", restored.FoodTypes)
+	}
+	if restored.BacteriaCount() != w.BacteriaCount() || restored.FoodCount() != w.FoodCount() {
+		t.Errorf("expected %d bacteria and %d food restored, got %d and %d",
+			w.BacteriaCount(), w.FoodCount(), restored.BacteriaCount(), restored.FoodCount())
+	}
+}
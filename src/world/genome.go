@@ -0,0 +1,53 @@
+package world
+
+// GeneHistogram counts how many bugs carry each value of each turn gene.
+// Bugs steered by a NetworkBrain are left out, as their turn genes never
+// change.
+type GeneHistogram struct {
+	Min    int     `json:"min"`
+	Max    int     `json:"max"`
+	Counts [][]int `json:"counts"` // Counts[gene][value-Min]
+}
+
+func NewGeneHistogram(all []*Bug) GeneHistogram {
+	bugs := []*Bug{}
+	for _, b := range all {
+		if _, ok := b.brain.(*NetworkBrain); !ok {
+			bugs = append(bugs, b)
+		}
+	}
+
+	result := GeneHistogram{}
+	if len(bugs) == 0 {
+		return result
+	}
+
+	result.Min = bugs[0].geneValue[0]
+	result.Max = bugs[0].geneValue[0]
+	for _, b := range bugs {
+		for _, v := range b.geneValue {
+			result.Min = min(result.Min, v)
+			result.Max = max(result.Max, v)
+		}
+	}
+
+	result.Counts = make([][]int, len(bugs[0].geneValue))
+	for i := range result.Counts {
+		result.Counts[i] = make([]int, result.Max-result.Min+1)
+	}
+	for _, b := range bugs {
+		for i, v := range b.geneValue {
+			result.Counts[i][v-result.Min]++
+		}
+	}
+
+	return result
+}
+
+// Count returns the number of bugs whose gene has the given value.
+func (h GeneHistogram) Count(gene, value int) int {
+	if gene >= len(h.Counts) || value < h.Min || value > h.Max {
+		return 0
+	}
+	return h.Counts[gene][value-h.Min]
+}
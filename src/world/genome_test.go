@@ -0,0 +1,44 @@
+package world
+
+import (
+	"testing"
+)
+
+func TestNewGeneHistogram(t *testing.T) {
+	bugs := []*Bug{
+		{geneValue: []int{3, -1, 0, 0, 0, 0}},
+		{geneValue: []int{3, 1, 0, 0, 0, -2}},
+		{geneValue: []int{2, 1, 0, 0, 0, 0}},
+	}
+
+	h := NewGeneHistogram(bugs)
+
+	if h.Min != -2 || h.Max != 3 {
+		t.Fatalf("expected range -2..3, got %d..%d", h.Min, h.Max)
+	}
+
+	tests := []struct {
+		gene, value, expected int
+	}{
+		{0, 3, 2},
+		{0, 2, 1},
+		{1, 1, 2},
+		{1, -1, 1},
+		{2, 0, 3},
+		{5, -2, 1},
+		{5, 0, 2},
+		{5, 7, 0},
+	}
+	for _, test := range tests {
+		if c := h.Count(test.gene, test.value); c != test.expected {
+			t.Errorf("gene %d value %d: expected %d, got %d", test.gene, test.value, test.expected, c)
+		}
+	}
+}
+
+func TestGeneHistogramEmptyPopulation(t *testing.T) {
+	h := NewGeneHistogram(nil)
+	if h.Count(0, 0) != 0 {
+		t.Error("expected no counts for an empty population")
+	}
+}
@@ -0,0 +1,157 @@
+package world
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CUSTOM_GEOMETRY is the name given to a geometry read by ParseDirections.
+const CUSTOM_GEOMETRY = "custom"
+
+// MAX_STEP is the furthest a custom heading can move a bug along either
+// axis in one cycle.
+const MAX_STEP = 4
+
+// Geometry is the table of headings a bug can move in, listed in turning
+// order. A bug carries one gene per heading, where gene i weights turning i
+// steps from its current heading, so the genome length follows the geometry.
+type Geometry struct {
+	Name       string  `json:"name"`
+	Directions []Point `json:"directions"` // offset moved each cycle for each heading
+}
+
+// HexGeometry is Palmiter's original six headings, a hexagonal lattice laid
+// over the square grid of cells.
+func HexGeometry() Geometry {
+	return Geometry{
+		Name: "hex",
+		Directions: []Point{
+			{X: 0, Y: 2},
+			{X: 2, Y: 1},
+			{X: 2, Y: -1},
+			{X: 0, Y: -2},
+			{X: -2, Y: -1},
+			{X: -2, Y: 1},
+		},
+	}
+}
+
+// Square4Geometry moves along the four sides of a square grid.
+func Square4Geometry() Geometry {
+	return Geometry{
+		Name: "square4",
+		Directions: []Point{
+			{X: 0, Y: 2},
+			{X: 2, Y: 0},
+			{X: 0, Y: -2},
+			{X: -2, Y: 0},
+		},
+	}
+}
+
+// Square8Geometry moves along the sides and diagonals of a square grid.
+func Square8Geometry() Geometry {
+	return Geometry{
+		Name: "square8",
+		Directions: []Point{
+			{X: 0, Y: 2},
+			{X: 2, Y: 2},
+			{X: 2, Y: 0},
+			{X: 2, Y: -2},
+			{X: 0, Y: -2},
+			{X: -2, Y: -2},
+			{X: -2, Y: 0},
+			{X: -2, Y: 2},
+		},
+	}
+}
+
+var geometries = map[string]func() Geometry{
+	"hex":     HexGeometry,
+	"square4": Square4Geometry,
+	"square8": Square8Geometry,
+}
+
+// GeometryNames lists the names of the built-in geometries.
+func GeometryNames() []string {
+	result := make([]string, 0, len(geometries))
+	for name := range geometries {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// GeometryByName returns one of the built-in geometries.
+func GeometryByName(name string) (Geometry, error) {
+	g, ok := geometries[name]
+	if !ok {
+		return Geometry{}, fmt.Errorf("unknown geometry %q", name)
+	}
+	return g(), nil
+}
+
+// ParseDirections reads a custom geometry written as its headings in
+// turning order, each an offset "X,Y", e.g. "0,2 2,0 0,-2 -2,0" for
+// square4.
+func ParseDirections(s string) (Geometry, error) {
+	result := Geometry{Name: CUSTOM_GEOMETRY}
+	for _, field := range strings.Fields(s) {
+		x, y, ok := strings.Cut(field, ",")
+		if !ok {
+			return Geometry{}, fmt.Errorf("direction %q needs X,Y", field)
+		}
+		var d Point
+		var err error
+		if d.X, err = strconv.Atoi(x); err != nil {
+			return Geometry{}, fmt.Errorf("invalid number %q in direction %q", x, field)
+		}
+		if d.Y, err = strconv.Atoi(y); err != nil {
+			return Geometry{}, fmt.Errorf("invalid number %q in direction %q", y, field)
+		}
+		result.Directions = append(result.Directions, d)
+	}
+
+	if err := result.Validate(); err != nil {
+		return Geometry{}, err
+	}
+	return result, nil
+}
+
+// DirectionsString writes the headings of a geometry the way
+// ParseDirections reads them.
+func (g Geometry) DirectionsString() string {
+	parts := make([]string, len(g.Directions))
+	for i, d := range g.Directions {
+		parts[i] = fmt.Sprintf("%d,%d", d.X, d.Y)
+	}
+	return strings.Join(parts, " ")
+}
+
+// GenomeLength is the number of turn genes a bug needs in this geometry.
+func (g Geometry) GenomeLength() int {
+	return len(g.Directions)
+}
+
+func (g Geometry) Validate() error {
+	if len(g.Directions) < 2 {
+		return fmt.Errorf("geometry %q needs at least two directions", g.Name)
+	}
+
+	seen := map[Point]bool{}
+	for _, d := range g.Directions {
+		if d.X == 0 && d.Y == 0 {
+			return fmt.Errorf("geometry %q has a direction that does not move", g.Name)
+		}
+		if abs(d.X) > MAX_STEP || abs(d.Y) > MAX_STEP {
+			return fmt.Errorf("geometry %q has direction %d,%d, more than %d cells away", g.Name, d.X, d.Y, MAX_STEP)
+		}
+		if seen[d] {
+			return fmt.Errorf("geometry %q lists direction %d,%d twice", g.Name, d.X, d.Y)
+		}
+		seen[d] = true
+	}
+	return nil
+}
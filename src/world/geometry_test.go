@@ -0,0 +1,87 @@
+package world
+
+import (
+	"testing"
+)
+
+func TestGeometryByName(t *testing.T) {
+	for _, name := range GeometryNames() {
+		g, err := GeometryByName(name)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		if err := g.Validate(); err != nil {
+			t.Errorf("%s: invalid geometry: %v", name, err)
+		}
+	}
+
+	if _, err := GeometryByName("triangle"); err == nil {
+		t.Error("expected an error for an unknown geometry")
+	}
+}
+
+func TestBugsMoveInGeometry(t *testing.T) {
+	for _, name := range GeometryNames() {
+		geometry, _ := GeometryByName(name)
+
+		w := NewGameWorld(100, 100)
+		w.Geometry = geometry
+		w.Initialize()
+
+		for _, b := range w.Bugs() {
+			if len(b.GeneValues()) != geometry.GenomeLength() {
+				t.Fatalf("%s: expected %d genes, got %d", name, geometry.GenomeLength(), len(b.GeneValues()))
+			}
+		}
+
+		for range 50 {
+			before := map[*Bug]Point{}
+			for _, b := range w.Bugs() {
+				before[b] = Point{X: b.X, Y: b.Y}
+			}
+			w.Next()
+
+			for _, b := range w.Bugs() {
+				start, ok := before[b]
+				if !ok {
+					continue
+				}
+				offset := geometry.Directions[b.Direction()]
+				x := (start.X + offset.X + w.Width) % w.Width
+				y := (start.Y + offset.Y + w.Height) % w.Height
+				if b.X != x || b.Y != y {
+					t.Fatalf("%s: bug moved from %v to %d, %d, expected %d, %d", name, start, b.X, b.Y, x, y)
+				}
+			}
+		}
+
+		if h := w.GeneHistogram(); len(h.Counts) != geometry.GenomeLength() {
+			t.Errorf("%s: expected a histogram of %d genes, got %d", name, geometry.GenomeLength(), len(h.Counts))
+		}
+	}
+}
+
+func TestParseDirections(t *testing.T) {
+	g, err := ParseDirections("0,2 2,0 0,-2 -2,0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if g.Name != CUSTOM_GEOMETRY || g.DirectionsString() != Square4Geometry().DirectionsString() {
+		t.Errorf("expected the square4 headings, got %s %s", g.Name, g.DirectionsString())
+	}
+
+	for _, input := range []string{
+		"",
+		"0,2",
+		"0,2 2",
+		"0,2 a,0",
+		"0,2 0,0",
+		"0,2 0,2",
+		"0,2 5,0",
+	} {
+		if _, err := ParseDirections(input); err == nil {
+			t.Errorf("expected %q to be rejected", input)
+		}
+	}
+}
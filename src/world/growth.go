@@ -0,0 +1,106 @@
+package world
+
+import (
+	"fmt"
+)
+
+type GrowthModel int
+
+const (
+	RANDOM_GROWTH    GrowthModel = iota // bacteria reseed at random empty cells
+	SPREADING_GROWTH                    // bacteria colonise the cells next to them
+)
+
+// Growth decides how bacteria regrow after being eaten. With
+// SPREADING_GROWTH, every bacterium has a SpreadRate in 1000 chance each
+// cycle of seeding one of its eight neighbours. The chance falls as the
+// bacteria approach the carrying capacity, so growth is logistic, and food
+// ends up in patches rather than scattered evenly.
+type Growth struct {
+	Model            GrowthModel `json:"model"`
+	SpreadRate       int         `json:"spreadRate"`       // chance in 1000 of a bacterium spreading each cycle
+	CarryingCapacity int         `json:"carryingCapacity"` // percentage of the world food of every kind can cover
+}
+
+func DefaultGrowth() Growth {
+	return Growth{
+		Model:            RANDOM_GROWTH,
+		SpreadRate:       20,
+		CarryingCapacity: 10,
+	}
+}
+
+// ParseGrowthModel reads a growth model written as "random" or "spread".
+func ParseGrowthModel(s string) (GrowthModel, error) {
+	switch s {
+	case "random":
+		return RANDOM_GROWTH, nil
+	case "spread", "spreading":
+		return SPREADING_GROWTH, nil
+	}
+	return RANDOM_GROWTH, fmt.Errorf("unknown growth model %q", s)
+}
+
+func (m GrowthModel) String() string {
+	if m == SPREADING_GROWTH {
+		return "spread"
+	}
+	return "random"
+}
+
+func (g Growth) Validate() error {
+	if g.Model != RANDOM_GROWTH && g.Model != SPREADING_GROWTH {
+		return fmt.Errorf("unknown growth model %d", g.Model)
+	}
+	if g.SpreadRate < 0 || g.SpreadRate > 1000 {
+		return fmt.Errorf("spread rate must be between 0 and 1000")
+	}
+	if g.CarryingCapacity < 0 || g.CarryingCapacity > 100 {
+		return fmt.Errorf("carrying capacity must be between 0 and 100")
+	}
+	return nil
+}
+
+var spreadOffsets = []Point{
+	{-1, -1}, {0, -1}, {1, -1},
+	{-1, 0}, {1, 0},
+	{-1, 1}, {0, 1}, {1, 1},
+}
+
+// spread grows the bacteria into neighbouring cells, wrapping around the
+// edges of a torus but not past walls or other edges. Cells colonised this
+// cycle only start spreading the next, and the same kind of food spreads as
+// the cell it came from.
+func (w *GameWorld) spread() {
+	capacity := w.Width * w.Height * w.scheduled(CAPACITY_PARAM, w.Growth.CarryingCapacity) / 100
+	if w.foodCount >= capacity {
+		return
+	}
+	chance := float64(w.scheduled(SPREAD_RATE_PARAM, w.Growth.SpreadRate)) / 1000 * (1 - float64(w.foodCount)/float64(capacity))
+
+	type colony struct {
+		pos   int
+		value byte
+	}
+	colonies := []colony{}
+	for i, v := range w.cells {
+		if v == 0 || w.rng.Float64() >= chance {
+			continue
+		}
+
+		offset := spreadOffsets[w.rng.IntN(len(spreadOffsets))]
+		x, y, ok := w.wrap(i%w.Width+offset.X, i/w.Width+offset.Y)
+		if ok && !w.isWall(x, y) {
+			colonies = append(colonies, colony{pos: y*w.Width + x, value: v})
+		}
+	}
+
+	for _, c := range colonies {
+		if w.foodCount >= capacity {
+			break
+		}
+		if w.cells[c.pos] == 0 {
+			w.growFood(c.pos, c.value)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package world
+
+import (
+	"testing"
+)
+
+func TestSpreadingGrowthStaysNextToBacteria(t *testing.T) {
+	w := NewGameWorld(50, 50)
+	w.Seed = 1
+	w.InitialBacteria = 0
+	w.InitialBugCount = 0
+	w.Growth = Growth{Model: SPREADING_GROWTH, SpreadRate: 500, CarryingCapacity: 50}
+	w.Initialize()
+	w.growFood(0, 1)
+
+	const cycles = 5
+	for range cycles {
+		w.Next()
+	}
+
+	if w.BacteriaCount() <= 1 {
+		t.Fatal("expected the bacteria to spread")
+	}
+	for x := range w.Width {
+		for y := range w.Height {
+			if v, _ := w.GetCell(x, y); v == 0 {
+				continue
+			}
+			if dx, dy := w.distance(x, y, 0, 0); dx > cycles || dy > cycles {
+				t.Errorf("bacteria at %d, %d is too far from where it started", x, y)
+			}
+		}
+	}
+}
+
+func TestSpreadingGrowthStopsAtCarryingCapacity(t *testing.T) {
+	w := NewGameWorld(20, 20)
+	w.Seed = 1
+	w.InitialBacteria = 5
+	w.InitialBugCount = 0
+	w.Growth = Growth{Model: SPREADING_GROWTH, SpreadRate: 1000, CarryingCapacity: 25}
+	w.Initialize()
+
+	for range 500 {
+		w.Next()
+		if w.BacteriaCount() > 100 {
+			t.Fatalf("expected at most 100 bacteria, got %d", w.BacteriaCount())
+		}
+	}
+	if w.BacteriaCount() < 90 {
+		t.Errorf("expected the bacteria to approach the carrying capacity, got %d", w.BacteriaCount())
+	}
+}
+
+func TestParseGrowthModel(t *testing.T) {
+	for _, m := range []GrowthModel{RANDOM_GROWTH, SPREADING_GROWTH} {
+		if parsed, err := ParseGrowthModel(m.String()); err != nil || parsed != m {
+			t.Errorf("%v did not round trip, got %v, %v", m, parsed, err)
+		}
+	}
+	if _, err := ParseGrowthModel("diffuse"); err == nil {
+		t.Error("expected an error for an unknown growth model")
+	}
+}
@@ -0,0 +1,138 @@
+package world
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// LineageRecord describes one bug in the family tree of a run.
+type LineageRecord struct {
+	ID             int    `json:"id"`
+	ParentID       int    `json:"parentId"`         // 0 for bugs with no recorded parent
+	MateID         int    `json:"mateId,omitempty"` // the other parent of a bug bred by crossover
+	BirthCycle     int    `json:"birthCycle"`
+	DeathCycle     int    `json:"deathCycle"` // -1 while the bug is alive
+	Genome         []int  `json:"genome"`
+	SenseGenes     []int  `json:"senseGenes,omitempty"` // nil unless the world has Sensing on
+	Brain          string `json:"brain,omitempty"`      // empty for a GeneBrain
+	BrainGenes     []int  `json:"brainGenes,omitempty"` // the weights of a NetworkBrain
+	Classification string `json:"classification"`
+	Predator       bool   `json:"predator,omitempty"` // a predator, whose family tree is separate from the bugs'
+}
+
+// Lineage records every bug born during a run, so the phylogeny of the
+// population can be reconstructed afterwards.
+type Lineage struct {
+	records []*LineageRecord
+	byID    map[int]*LineageRecord
+}
+
+func NewLineage() *Lineage {
+	return &Lineage{
+		records: []*LineageRecord{},
+		byID:    map[int]*LineageRecord{},
+	}
+}
+
+// Records returns every recorded bug in the order they were born.
+func (l *Lineage) Records() []*LineageRecord {
+	return l.records
+}
+
+func (l *Lineage) Record(id int) *LineageRecord {
+	return l.byID[id]
+}
+
+func (l *Lineage) born(b *Bug, cycle int) {
+	var brain string
+	var brainGenes []int
+	if network, ok := b.brain.(*NetworkBrain); ok {
+		brain = network.Name()
+		brainGenes = network.Weights()
+	}
+	l.add(&LineageRecord{
+		ID:             b.ID,
+		ParentID:       b.ParentID,
+		MateID:         b.MateID,
+		BirthCycle:     cycle,
+		DeathCycle:     -1,
+		Genome:         b.GeneValues(),
+		SenseGenes:     b.SenseGenes(),
+		Brain:          brain,
+		BrainGenes:     brainGenes,
+		Classification: b.Classification,
+	})
+}
+
+func (l *Lineage) bornPredator(p *Bug, cycle int) {
+	l.born(p, cycle)
+	l.byID[p.ID].Predator = true
+}
+
+func (l *Lineage) add(record *LineageRecord) {
+	l.records = append(l.records, record)
+	l.byID[record.ID] = record
+}
+
+func (l *Lineage) died(b *Bug, cycle int) {
+	if record, ok := l.byID[b.ID]; ok {
+		record.DeathCycle = cycle
+	}
+}
+
+// WriteJSON writes every lineage record as a JSON array.
+func (l *Lineage) WriteJSON(out io.Writer) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(l.records)
+}
+
+// WriteNewick writes the lineage as a Newick tree. Each node is named after
+// its bug ID and the branch length is the lifetime of the bug, measured up
+// to currentCycle for bugs that are still alive. Bugs without a recorded
+// parent hang off a single unnamed root.
+func (l *Lineage) WriteNewick(out io.Writer, currentCycle int) error {
+	children := map[int][]*LineageRecord{}
+	roots := []*LineageRecord{}
+	for _, r := range l.records {
+		if _, ok := l.byID[r.ParentID]; ok {
+			children[r.ParentID] = append(children[r.ParentID], r)
+		} else {
+			roots = append(roots, r)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("(")
+	for i, r := range roots {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		writeNewickNode(&sb, r, children, currentCycle)
+	}
+	sb.WriteString(");\n")
+
+	_, err := io.WriteString(out, sb.String())
+	return err
+}
+
+func writeNewickNode(sb *strings.Builder, r *LineageRecord, children map[int][]*LineageRecord, currentCycle int) {
+	if kids := children[r.ID]; len(kids) > 0 {
+		sb.WriteString("(")
+		for i, kid := range kids {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			writeNewickNode(sb, kid, children, currentCycle)
+		}
+		sb.WriteString(")")
+	}
+
+	end := r.DeathCycle
+	if end < 0 {
+		end = currentCycle
+	}
+	fmt.Fprintf(sb, "%d:%d", r.ID, end-r.BirthCycle)
+}
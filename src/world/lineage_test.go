@@ -0,0 +1,61 @@
+package world
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestLineageRecordsBirthsAndDeaths(t *testing.T) {
+	w := NewGameWorld(100, 100)
+	w.InitialBugCount = 2
+	w.RecordLineage = true
+	w.Initialize()
+
+	parent := w.Bugs()[0]
+	parent.Age = w.Rules.ReproduceAge + 1
+	parent.Energy = w.Rules.ReproduceEnergy + 1
+	dead := w.Bugs()[1]
+	dead.Energy = 0
+	w.Next()
+
+	lineage := w.Lineage()
+	if len(lineage.Records()) != 4 {
+		t.Fatalf("expected 4 lineage records, got %d", len(lineage.Records()))
+	}
+
+	if r := lineage.Record(parent.ID); r.DeathCycle != 1 {
+		t.Errorf("expected the parent to end at cycle 1, got %d", r.DeathCycle)
+	}
+	if r := lineage.Record(dead.ID); r.DeathCycle != 1 {
+		t.Errorf("expected the dead bug to end at cycle 1, got %d", r.DeathCycle)
+	}
+
+	for _, b := range w.Bugs() {
+		r := lineage.Record(b.ID)
+		if r == nil {
+			t.Fatalf("expected a record for bug %d", b.ID)
+		}
+		if r.ParentID != parent.ID || r.BirthCycle != 1 || r.DeathCycle != -1 || !slices.Equal(r.Genome, b.GeneValues()) {
+			t.Errorf("unexpected record for child %d: %+v", b.ID, r)
+		}
+	}
+}
+
+func TestLineageWriteNewick(t *testing.T) {
+	lineage := NewLineage()
+	lineage.add(&LineageRecord{ID: 1, BirthCycle: 0, DeathCycle: 100})
+	lineage.add(&LineageRecord{ID: 2, BirthCycle: 0, DeathCycle: 50})
+	lineage.add(&LineageRecord{ID: 3, ParentID: 1, BirthCycle: 100, DeathCycle: -1})
+	lineage.add(&LineageRecord{ID: 4, ParentID: 1, BirthCycle: 100, DeathCycle: 130})
+
+	var buf bytes.Buffer
+	if err := lineage.WriteNewick(&buf, 200); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "((3:100,4:30)1:100,2:50);\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
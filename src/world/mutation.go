@@ -0,0 +1,125 @@
+package world
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+)
+
+// MutationPolicy decides how the genes of a child change when it splits off
+// its parent. The zero value is the original scheme, where exactly one random
+// gene changes by exactly one.
+type MutationPolicy struct {
+	Off      bool    `json:"off,omitempty"` // children are exact copies, for control runs
+	GeneRate float64 `json:"geneRate"`      // chance of each gene mutating, 0 mutates exactly one gene
+	StepSize float64 `json:"stepSize"`      // standard deviation of Gaussian steps, 0 steps by exactly one
+	MinGene  int     `json:"minGene"`       // genes are kept within MinGene..MaxGene,
+	MaxGene  int     `json:"maxGene"`       // unless both are 0
+}
+
+func (p MutationPolicy) Validate() error {
+	if p.GeneRate < 0 || p.GeneRate > 1 {
+		return fmt.Errorf("mutation rate must be between 0 and 1")
+	}
+	if p.StepSize < 0 {
+		return fmt.Errorf("mutation step size must not be negative")
+	}
+	if p.MinGene > p.MaxGene {
+		return fmt.Errorf("minimum gene value must not be more than the maximum")
+	}
+	return nil
+}
+
+// Bounded reports whether genes are kept within MinGene..MaxGene.
+func (p MutationPolicy) Bounded() bool {
+	return p.MinGene != 0 || p.MaxGene != 0
+}
+
+// String describes the policy in a single line, as written to history
+// exports.
+func (p MutationPolicy) String() string {
+	if p.Off {
+		return "off"
+	}
+
+	parts := []string{}
+	if p.GeneRate == 0 {
+		parts = append(parts, "one gene")
+	} else {
+		parts = append(parts, "rate "+strconv.FormatFloat(p.GeneRate, 'f', -1, 64))
+	}
+	if p.StepSize == 0 {
+		parts = append(parts, "step 1")
+	} else {
+		parts = append(parts, "gaussian "+strconv.FormatFloat(p.StepSize, 'f', -1, 64))
+	}
+	if p.Bounded() {
+		parts = append(parts, fmt.Sprintf("range %d..%d", p.MinGene, p.MaxGene))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Apply mutates the genes of b. The sign of delta gives the direction of
+// every step, so the two children of a split mutate in opposite directions.
+// Gaussian steps are rounded and never smaller than one.
+func (p MutationPolicy) Apply(rng *rand.Rand, b *Bug, delta int) {
+	genes := b.genes()
+	if p.Off || len(genes) == 0 {
+		return
+	}
+
+	if p.GeneRate == 0 {
+		gene := genes[rng.IntN(len(genes))]
+		*gene = p.mutateGene(rng, *gene, delta)
+	} else {
+		for _, gene := range genes {
+			if rng.Float64() < p.GeneRate {
+				*gene = p.mutateGene(rng, *gene, delta)
+			}
+		}
+	}
+	b.updateWeights()
+}
+
+// mutatesPreference reports whether the food preference of b mutates. It
+// mutates at GeneRate, or when GeneRate is 0 as though it were one more gene
+// the single mutation could pick.
+func (p MutationPolicy) mutatesPreference(rng *rand.Rand, b *Bug) bool {
+	if p.Off {
+		return false
+	}
+	if p.GeneRate == 0 {
+		return rng.IntN(len(b.genes())+1) == 0
+	}
+	return rng.Float64() < p.GeneRate
+}
+
+// Clamp brings the genes of b within MinGene..MaxGene, so bugs start inside
+// the range their mutations keep to.
+func (p MutationPolicy) Clamp(b *Bug) {
+	if !p.Bounded() {
+		return
+	}
+	for _, gene := range b.genes() {
+		*gene = max(p.MinGene, min(p.MaxGene, *gene))
+	}
+	b.updateWeights()
+}
+
+func (p MutationPolicy) mutateGene(rng *rand.Rand, value, delta int) int {
+	if p.StepSize > 0 {
+		step := max(1, int(math.Round(math.Abs(rng.NormFloat64()*p.StepSize))))
+		if delta < 0 {
+			step = -step
+		}
+		delta = step
+	}
+
+	value += delta
+	if p.Bounded() {
+		value = max(p.MinGene, min(p.MaxGene, value))
+	}
+	return value
+}
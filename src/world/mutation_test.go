@@ -0,0 +1,142 @@
+package world
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestDefaultMutationChangesOneGene(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 1))
+	b := bugWithGenes(0, 0, 0, 0, 0, 0)
+	MutationPolicy{}.Apply(rng, b, -1)
+
+	changed := 0
+	for _, v := range b.geneValue {
+		if v == -1 {
+			changed++
+		} else if v != 0 {
+			t.Errorf("expected a step of exactly one, got %v", b.geneValue)
+		}
+	}
+	if changed != 1 || b.totalOfWeights != 1 {
+		t.Errorf("expected one gene to change, got %v", b.geneValue)
+	}
+}
+
+func TestMutationPolicies(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy MutationPolicy
+		check  func(genes []int) bool
+	}{
+		{"off", MutationPolicy{Off: true, GeneRate: 1}, func(genes []int) bool {
+			return slices.Equal(genes, []int{0, 0, 0, 0, 0, 0})
+		}},
+		{"every gene", MutationPolicy{GeneRate: 1}, func(genes []int) bool {
+			return slices.Equal(genes, []int{1, 1, 1, 1, 1, 1})
+		}},
+		{"gaussian", MutationPolicy{GeneRate: 1, StepSize: 3}, func(genes []int) bool {
+			return !slices.Contains(genes, 0) && slices.IndexFunc(genes, func(v int) bool { return v < 0 }) < 0
+		}},
+		{"bounded", MutationPolicy{GeneRate: 1, StepSize: 10, MinGene: -2, MaxGene: 2}, func(genes []int) bool {
+			return slices.Max(genes) <= 2 && slices.Min(genes) >= 1
+		}},
+	}
+
+	rng := rand.New(rand.NewPCG(1, 1))
+	for _, tt := range tests {
+		for range 20 {
+			b := bugWithGenes(0, 0, 0, 0, 0, 0)
+			tt.policy.Apply(rng, b, 1)
+			if !tt.check(b.geneValue) {
+				t.Errorf("%s: unexpected genes %v", tt.name, b.geneValue)
+				break
+			}
+		}
+	}
+}
+
+func TestMutationPolicyFoodPreference(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy MutationPolicy
+		want   func(mutated int) bool
+	}{
+		{"off", MutationPolicy{Off: true, GeneRate: 1}, func(mutated int) bool { return mutated == 0 }},
+		{"every gene", MutationPolicy{GeneRate: 1}, func(mutated int) bool { return mutated == 100 }},
+		{"one gene", MutationPolicy{}, func(mutated int) bool { return mutated > 0 && mutated < 40 }},
+	}
+
+	rng := rand.New(rand.NewPCG(1, 1))
+	for _, tt := range tests {
+		mutated := 0
+		for range 100 {
+			if tt.policy.mutatesPreference(rng, bugWithGenes(0, 0, 0, 0, 0, 0)) {
+				mutated++
+			}
+		}
+		if !tt.want(mutated) {
+			t.Errorf("%s: food preference mutated %d times in 100", tt.name, mutated)
+		}
+	}
+}
+
+func TestStartingGenesKeepToTheMutationRange(t *testing.T) {
+	w := NewGameWorld(50, 50)
+	w.Seed = 3
+	w.Sensing = true
+	w.Mutation = MutationPolicy{MinGene: 0, MaxGene: 1}
+	w.Initialize()
+
+	for _, b := range w.Bugs() {
+		for _, gene := range b.genes() {
+			if *gene < 0 || *gene > 1 {
+				t.Fatalf("bug %d starts with gene %d outside 0..1", b.ID, *gene)
+			}
+		}
+	}
+}
+
+func TestMutationPolicyValidate(t *testing.T) {
+	valid := []MutationPolicy{{}, {Off: true}, {GeneRate: 0.5, StepSize: 1.5, MinGene: -5, MaxGene: 5}}
+	for _, p := range valid {
+		if err := p.Validate(); err != nil {
+			t.Errorf("expected %+v to be valid, got %v", p, err)
+		}
+	}
+
+	invalid := []MutationPolicy{{GeneRate: -0.1}, {GeneRate: 1.5}, {StepSize: -1}, {MinGene: 3, MaxGene: 2}}
+	for _, p := range invalid {
+		if err := p.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", p)
+		}
+	}
+}
+
+func TestMutationPolicyIsRecorded(t *testing.T) {
+	w := NewGameWorld(100, 100)
+	w.Seed = 3
+	w.Mutation = MutationPolicy{GeneRate: 0.25, StepSize: 2, MinGene: -4, MaxGene: 4}
+	w.Initialize()
+	for range w.Rules.HistoryInterval {
+		w.Next()
+	}
+
+	history := w.History()
+	if expected := "rate 0.25; gaussian 2; range -4..4"; history[len(history)-1].Mutation != expected {
+		t.Errorf("expected history to record %q, got %q", expected, history[len(history)-1].Mutation)
+	}
+
+	data, err := w.MarshalSnapshot()
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	restored := NewGameWorld(10, 10)
+	if err := restored.UnmarshalSnapshot(data); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if restored.Mutation != w.Mutation {
+		t.Errorf("expected mutation policy %+v, got %+v", w.Mutation, restored.Mutation)
+	}
+}
@@ -0,0 +1,40 @@
+package world
+
+// Neighbors returns the cells within radius of x, y along both axes, the
+// square a bug with that feeding radius covers, listed row by row. On a
+// torus the square wraps around the edges of the world, and a square wider
+// than the world covers each cell only once. With any other boundary the
+// cells past the edges are left out.
+func (w *GameWorld) Neighbors(x, y, radius int) []Point {
+	xs := w.span(x, radius, w.Width)
+	ys := w.span(y, radius, w.Height)
+
+	result := make([]Point, 0, len(xs)*len(ys))
+	for _, ny := range ys {
+		for _, nx := range xs {
+			result = append(result, Point{X: nx, Y: ny})
+		}
+	}
+	return result
+}
+
+// span returns the coordinates within radius of c along an axis of the
+// given size, in order.
+func (w *GameWorld) span(c, radius, size int) []int {
+	result := []int{}
+	if w.Boundary == TORUS_BOUNDARY && 2*radius+1 >= size {
+		for i := range size {
+			result = append(result, i)
+		}
+		return result
+	}
+
+	for i := c - radius; i <= c+radius; i++ {
+		if w.Boundary == TORUS_BOUNDARY {
+			result = append(result, (i%size+size)%size)
+		} else if i >= 0 && i < size {
+			result = append(result, i)
+		}
+	}
+	return result
+}
@@ -0,0 +1,116 @@
+package world
+
+import (
+	"fmt"
+)
+
+// PredatorRules holds the tunable constants of the predators, which move
+// like bugs but hunt the bugs instead of eating bacteria.
+type PredatorRules struct {
+	ReproduceAge    int `json:"reproduceAge"`    // a predator must be older than this to split
+	ReproduceEnergy int `json:"reproduceEnergy"` // a predator must have more energy than this to split
+	MaxEnergy       int `json:"maxEnergy"`       // energy a predator can store
+	StartingEnergy  int `json:"startingEnergy"`  // energy of the initial predators
+	EnergyPerBug    int `json:"energyPerBug"`    // energy gained for each bug eaten
+	FeedingRadius   int `json:"feedingRadius"`   // 1 == 3x3 footprint, 2 == 5x5, etc.
+}
+
+func DefaultPredatorRules() PredatorRules {
+	return PredatorRules{
+		ReproduceAge:    1000,
+		ReproduceEnergy: 3000,
+		MaxEnergy:       6000,
+		StartingEnergy:  3000,
+		EnergyPerBug:    800,
+		FeedingRadius:   4,
+	}
+}
+
+func (r PredatorRules) Validate() error {
+	if r.ReproduceAge < 0 {
+		return fmt.Errorf("predator reproduction age must not be negative")
+	}
+	if r.ReproduceEnergy < 0 {
+		return fmt.Errorf("predator reproduction energy must not be negative")
+	}
+	if r.MaxEnergy <= 0 {
+		return fmt.Errorf("predator max energy must be positive")
+	}
+	if r.StartingEnergy <= 0 {
+		return fmt.Errorf("predator starting energy must be positive")
+	}
+	if r.EnergyPerBug < 0 {
+		return fmt.Errorf("predator energy per bug must not be negative")
+	}
+	if r.FeedingRadius < 0 {
+		return fmt.Errorf("predator feeding radius must not be negative")
+	}
+	return nil
+}
+
+// updatePredators splits, moves and feeds the predators, the same way
+// updateBugs does for bugs, recording them in the lineage alongside the
+// bugs. The bugs a predator eats are removed from the world straight away.
+func (w *GameWorld) updatePredators() {
+	rules := w.Rules.Predator
+	nextPredators := []*Bug{}
+
+	for _, p := range w.predators {
+		if p.Age > rules.ReproduceAge && p.Energy > rules.ReproduceEnergy {
+			for _, delta := range []int{1, -1} {
+				child := p.NewBugFrom()
+				child.ID = w.nextBugID()
+				w.Mutation.Apply(w.rng, child, delta)
+				nextPredators = append(nextPredators, child)
+				if w.lineage != nil {
+					w.lineage.bornPredator(child, w.cycle)
+				}
+			}
+			if w.lineage != nil {
+				w.lineage.died(p, w.cycle)
+			}
+		} else if p.Energy > 0 {
+			nextPredators = append(nextPredators, p)
+		} else if w.lineage != nil {
+			w.lineage.died(p, w.cycle)
+		}
+	}
+
+	for _, p := range nextPredators {
+		if !p.Update(w.rng, w.geometry, w.Width, w.Height, w.Boundary, w.walls(p)) {
+			continue
+		}
+		p.Energy += w.bugsUnderPredator(p)
+		if p.Energy > rules.MaxEnergy {
+			p.Energy = rules.MaxEnergy
+		}
+	}
+
+	w.predators = nextPredators
+}
+
+func (w *GameWorld) bugsUnderPredator(p *Bug) int {
+	radius := w.Rules.Predator.FeedingRadius
+	survivors := w.bugs[:0]
+	eaten := 0
+	for _, b := range w.bugs {
+		dx, dy := w.distance(p.X, p.Y, b.X, b.Y)
+		if dx <= radius && dy <= radius {
+			eaten++
+			if w.lineage != nil {
+				w.lineage.died(b, w.cycle)
+			}
+			continue
+		}
+		survivors = append(survivors, b)
+	}
+	clear(w.bugs[len(survivors):])
+	w.bugs = survivors
+
+	return eaten * w.Rules.Predator.EnergyPerBug
+}
+
+// Predators returns the predators currently alive.
+func (w *GameWorld) Predators() []*Bug {
+	return w.predators
+}
@@ -0,0 +1,97 @@
+package world
+
+import (
+	"testing"
+)
+
+func TestPredatorEatsBugsInFootprint(t *testing.T) {
+	w := NewGameWorld(100, 100)
+	w.InitialBugCount = 0
+	w.Rules.Predator.FeedingRadius = 1
+	w.Rules.Predator.EnergyPerBug = 50
+	w.Initialize()
+
+	near := &Bug{X: 1, Y: 99}
+	far := &Bug{X: 10, Y: 10}
+	w.bugs = []*Bug{near, far}
+
+	energy := w.bugsUnderPredator(&Bug{X: 0, Y: 0})
+	if energy != 50 {
+		t.Errorf("expected 50 energy, got %d", energy)
+	}
+	if len(w.bugs) != 1 || w.bugs[0] != far {
+		t.Errorf("expected only the far bug to survive, got %+v", w.bugs)
+	}
+}
+
+func TestPredatorsReproduceAndStarve(t *testing.T) {
+	w := NewGameWorld(100, 100)
+	w.Seed = 1
+	w.InitialBugCount = 1
+	w.InitialPredatorCount = 2
+	w.Rules.Predator.FeedingRadius = 0
+	w.Initialize()
+	if len(w.Predators()) != 2 {
+		t.Fatalf("expected 2 predators, got %d", len(w.Predators()))
+	}
+
+	parent := w.Predators()[0]
+	parent.Age = w.Rules.Predator.ReproduceAge + 1
+	parent.Energy = w.Rules.Predator.ReproduceEnergy + 1
+	starving := w.Predators()[1]
+	starving.Energy = 0
+	w.Next()
+
+	predators := w.Predators()
+	if len(predators) != 2 {
+		t.Fatalf("expected the parent to split and the starving predator to die, got %d predators", len(predators))
+	}
+	for _, p := range predators {
+		if p.ParentID != parent.ID {
+			t.Errorf("expected a child of predator %d, got parent %d", parent.ID, p.ParentID)
+		}
+	}
+}
+
+func TestPredatorRulesValidate(t *testing.T) {
+	for _, change := range []func(r *PredatorRules){
+		func(r *PredatorRules) { r.ReproduceEnergy = -1 },
+		func(r *PredatorRules) { r.EnergyPerBug = -1 },
+		func(r *PredatorRules) { r.MaxEnergy = 0 },
+	} {
+		rules := DefaultPredatorRules()
+		change(&rules)
+		if err := rules.Validate(); err == nil {
+			t.Errorf("expected %+v to be rejected", rules)
+		}
+	}
+}
+
+func TestPredatorsInLineage(t *testing.T) {
+	w := NewGameWorld(100, 100)
+	w.Seed = 1
+	w.InitialBugCount = 1
+	w.InitialPredatorCount = 1
+	w.RecordLineage = true
+	w.Initialize()
+
+	parent := w.Predators()[0]
+	parent.Age = w.Rules.Predator.ReproduceAge + 1
+	parent.Energy = w.Rules.Predator.ReproduceEnergy + 1
+	w.Next()
+
+	if record := w.Lineage().Record(parent.ID); record == nil || !record.Predator || record.DeathCycle != w.Cycle() {
+		t.Fatalf("expected the parent predator to be recorded as dying on splitting, got %+v", record)
+	}
+	for _, child := range w.Predators() {
+		record := w.Lineage().Record(child.ID)
+		if record == nil || !record.Predator || record.ParentID != parent.ID {
+			t.Errorf("expected predator %d to be recorded as a child of %d, got %+v", child.ID, parent.ID, record)
+		}
+	}
+	for _, b := range w.Bugs() {
+		if w.Lineage().Record(b.ID).Predator {
+			t.Errorf("expected bug %d not to be recorded as a predator", b.ID)
+		}
+	}
+}
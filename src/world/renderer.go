@@ -0,0 +1,14 @@
+package world
+
+// Renderer draws a GameWorld onto some output surface. The simulation itself
+// never depends on a particular renderer, which lets the world package build
+// and run outside of the browser.
+type Renderer interface {
+	DrawBackground(screenView ScreenView, w *GameWorld) error
+	DrawFertility(w *GameWorld) error
+	DrawTerrain(w *GameWorld) error
+	DrawCells(w *GameWorld) error
+	DrawBugs(w *GameWorld) error
+	DrawHUD(screenView ScreenView, w *GameWorld) error
+	DrawReport(w *GameWorld) error
+}
@@ -0,0 +1,55 @@
+package world
+
+import (
+	"fmt"
+)
+
+// Rules holds the tunable constants of the simulation.
+type Rules struct {
+	ReproduceAge       int `json:"reproduceAge"`       // a bug must be older than this to split
+	ReproduceEnergy    int `json:"reproduceEnergy"`    // a bug must have more energy than this to split
+	MaxEnergy          int `json:"maxEnergy"`          // energy a bug can store
+	StartingEnergy     int `json:"startingEnergy"`     // energy of the initial bugs
+	EnergyPerBacterium int `json:"energyPerBacterium"` // energy gained for each bacterium eaten
+	FeedingRadius      int `json:"feedingRadius"`      // 1 == 3x3 footprint, 2 == 5x5, etc.
+	MatingRadius       int `json:"matingRadius"`       // how close two bugs must be to mate with sexual reproduction
+	HistoryInterval    int `json:"historyInterval"`    // cycles between history samples
+
+	Predator PredatorRules `json:"predator"`
+}
+
+func DefaultRules() Rules {
+	return Rules{
+		ReproduceAge:       800,
+		ReproduceEnergy:    1000,
+		MaxEnergy:          1500,
+		StartingEnergy:     400,
+		EnergyPerBacterium: 40,
+		FeedingRadius:      1,
+		MatingRadius:       2,
+		HistoryInterval:    20,
+		Predator:           DefaultPredatorRules(),
+	}
+}
+
+func (r Rules) Validate() error {
+	if r.ReproduceAge < 0 {
+		return fmt.Errorf("reproduction age must not be negative")
+	}
+	if r.MaxEnergy <= 0 {
+		return fmt.Errorf("max energy must be positive")
+	}
+	if r.StartingEnergy <= 0 {
+		return fmt.Errorf("starting energy must be positive")
+	}
+	if r.FeedingRadius < 0 {
+		return fmt.Errorf("feeding radius must not be negative")
+	}
+	if r.MatingRadius < 0 {
+		return fmt.Errorf("mating radius must not be negative")
+	}
+	if r.HistoryInterval <= 0 {
+		return fmt.Errorf("history interval must be positive")
+	}
+	return r.Predator.Validate()
+}
@@ -0,0 +1,251 @@
+package world
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type ScheduleKind int
+
+const (
+	SINE_SCHEDULE  ScheduleKind = iota // a smooth wave, for seasons
+	STEP_SCHEDULE                      // holds each value until the next point
+	TABLE_SCHEDULE                     // moves in a straight line between points
+)
+
+// SchedulePoint is the value a parameter takes at a cycle.
+type SchedulePoint struct {
+	Cycle int `json:"cycle"`
+	Value int `json:"value"`
+}
+
+// Schedule changes an environmental parameter of the world as the cycles go
+// by. A sine schedule swings around Mean by Amplitude once every Period
+// cycles, starting Phase cycles into the wave. Step and table schedules
+// follow their Points, starting over every Repeat cycles when Repeat is set.
+type Schedule struct {
+	Param string       `json:"param"` // one of ScheduleParams
+	Kind  ScheduleKind `json:"kind"`
+
+	Mean      int `json:"mean,omitempty"`
+	Amplitude int `json:"amplitude,omitempty"`
+	Period    int `json:"period,omitempty"`
+	Phase     int `json:"phase,omitempty"`
+
+	Points []SchedulePoint `json:"points,omitempty"`
+	Repeat int             `json:"repeat,omitempty"`
+}
+
+// The parameters a schedule can change. While scheduled, they stand in for
+// GameWorld.ReseedBacteria, Rules.EnergyPerBacterium, Growth.SpreadRate and
+// Growth.CarryingCapacity.
+const (
+	RESEED_PARAM           = "reseed"
+	BACTERIUM_ENERGY_PARAM = "bacterium-energy"
+	SPREAD_RATE_PARAM      = "spread-rate"
+	CAPACITY_PARAM         = "capacity"
+)
+
+// scheduleParams maps each parameter a schedule can change to the largest
+// value it can take.
+var scheduleParams = map[string]int{
+	RESEED_PARAM:           math.MaxInt,
+	BACTERIUM_ENERGY_PARAM: math.MaxInt,
+	SPREAD_RATE_PARAM:      1000,
+	CAPACITY_PARAM:         100,
+}
+
+// ScheduleParams returns the names of the parameters a schedule can change,
+// sorted alphabetically.
+func ScheduleParams() []string {
+	result := make([]string, 0, len(scheduleParams))
+	for name := range scheduleParams {
+		result = append(result, name)
+	}
+	slices.Sort(result)
+	return result
+}
+
+// ParseSchedule reads a schedule written as one of
+//
+//	PARAM sine MEAN AMPLITUDE PERIOD [PHASE]
+//	PARAM step CYCLE:VALUE ... [repeat CYCLES]
+//	PARAM table CYCLE:VALUE ... [repeat CYCLES]
+func ParseSchedule(s string) (*Schedule, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("schedule %q needs a parameter, a kind and values", s)
+	}
+
+	result := &Schedule{Param: fields[0]}
+	switch strings.ToLower(fields[1]) {
+	case "sine":
+		result.Kind = SINE_SCHEDULE
+		if len(fields) != 5 && len(fields) != 6 {
+			return nil, fmt.Errorf("sine schedule %q needs MEAN AMPLITUDE PERIOD [PHASE]", s)
+		}
+		values := []*int{&result.Mean, &result.Amplitude, &result.Period, &result.Phase}
+		for i, f := range fields[2:] {
+			n, err := strconv.Atoi(f)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q in schedule %q", f, s)
+			}
+			*values[i] = n
+		}
+	case "step", "table":
+		result.Kind = STEP_SCHEDULE
+		if strings.ToLower(fields[1]) == "table" {
+			result.Kind = TABLE_SCHEDULE
+		}
+		points := fields[2:]
+		if len(points) >= 2 && strings.ToLower(points[len(points)-2]) == "repeat" {
+			n, err := strconv.Atoi(points[len(points)-1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid repeat %q in schedule %q", points[len(points)-1], s)
+			}
+			result.Repeat = n
+			points = points[:len(points)-2]
+		}
+		for _, p := range points {
+			cycle, value, ok := strings.Cut(p, ":")
+			c, err1 := strconv.Atoi(cycle)
+			v, err2 := strconv.Atoi(value)
+			if !ok || err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("invalid point %q in schedule %q, expected CYCLE:VALUE", p, s)
+			}
+			result.Points = append(result.Points, SchedulePoint{Cycle: c, Value: v})
+		}
+	default:
+		return nil, fmt.Errorf("unknown schedule kind %q", fields[1])
+	}
+
+	if err := result.Validate(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ParseSchedules reads one schedule per line, ignoring blank lines.
+func ParseSchedules(s string) ([]*Schedule, error) {
+	result := []*Schedule{}
+	for _, line := range strings.Split(s, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		schedule, err := ParseSchedule(line)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, schedule)
+	}
+	return result, nil
+}
+
+func (s *Schedule) String() string {
+	if s.Kind == SINE_SCHEDULE {
+		return fmt.Sprintf("%s sine %d %d %d %d", s.Param, s.Mean, s.Amplitude, s.Period, s.Phase)
+	}
+
+	parts := []string{s.Param, "step"}
+	if s.Kind == TABLE_SCHEDULE {
+		parts[1] = "table"
+	}
+	for _, p := range s.Points {
+		parts = append(parts, fmt.Sprintf("%d:%d", p.Cycle, p.Value))
+	}
+	if s.Repeat > 0 {
+		parts = append(parts, "repeat", strconv.Itoa(s.Repeat))
+	}
+	return strings.Join(parts, " ")
+}
+
+func (s *Schedule) Validate() error {
+	if _, ok := scheduleParams[s.Param]; !ok {
+		return fmt.Errorf("unknown schedule parameter %q, expected one of %s", s.Param, strings.Join(ScheduleParams(), ", "))
+	}
+
+	switch s.Kind {
+	case SINE_SCHEDULE:
+		if s.Period <= 0 {
+			return fmt.Errorf("schedule %s needs a positive period", s)
+		}
+	case STEP_SCHEDULE, TABLE_SCHEDULE:
+		if len(s.Points) == 0 {
+			return fmt.Errorf("schedule %s needs at least one point", s)
+		}
+		for i := 1; i < len(s.Points); i++ {
+			if s.Points[i].Cycle <= s.Points[i-1].Cycle {
+				return fmt.Errorf("schedule %s needs its points in order of cycle", s)
+			}
+		}
+		if s.Repeat < 0 {
+			return fmt.Errorf("schedule %s has a negative repeat", s)
+		}
+	default:
+		return fmt.Errorf("unknown schedule kind %d", s.Kind)
+	}
+	return nil
+}
+
+// ValueAt returns the value of the parameter at a cycle.
+func (s *Schedule) ValueAt(cycle int) int {
+	if s.Kind == SINE_SCHEDULE {
+		angle := 2 * math.Pi * float64(cycle+s.Phase) / float64(s.Period)
+		return s.Mean + int(math.Round(float64(s.Amplitude)*math.Sin(angle)))
+	}
+
+	if s.Repeat > 0 {
+		cycle %= s.Repeat
+	}
+
+	i, found := slices.BinarySearchFunc(s.Points, cycle, func(p SchedulePoint, c int) int { return p.Cycle - c })
+	switch {
+	case found:
+		return s.Points[i].Value
+	case i == 0:
+		return s.Points[0].Value
+	case i == len(s.Points) || s.Kind == STEP_SCHEDULE:
+		return s.Points[i-1].Value
+	}
+
+	from, to := s.Points[i-1], s.Points[i]
+	return from.Value + (to.Value-from.Value)*(cycle-from.Cycle)/(to.Cycle-from.Cycle)
+}
+
+// applySchedules works out the value of every scheduled parameter for the
+// current cycle, kept within the range the parameter allows. The settings
+// the schedules stand in for are left as they are.
+func (w *GameWorld) applySchedules() {
+	if len(w.Schedules) == 0 {
+		w.environment = nil
+		return
+	}
+
+	w.environment = map[string]int{}
+	for _, s := range w.Schedules {
+		w.environment[s.Param] = max(0, min(scheduleParams[s.Param], s.ValueAt(w.cycle)))
+	}
+}
+
+// scheduled returns the value of a parameter this cycle: the value of its
+// schedule if it has one, and base otherwise.
+func (w *GameWorld) scheduled(param string, base int) int {
+	if v, ok := w.environment[param]; ok {
+		return v
+	}
+	return base
+}
+
+// scheduledValues returns the current value of every scheduled parameter.
+func (w *GameWorld) scheduledValues() map[string]int {
+	return w.environment
+}
+
+// ReseedRate returns the bacteria rate this cycle, which differs from
+// ReseedBacteria while a schedule changes it.
+func (w *GameWorld) ReseedRate() int {
+	return w.scheduled(RESEED_PARAM, w.ReseedBacteria)
+}
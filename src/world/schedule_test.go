@@ -0,0 +1,90 @@
+package world
+
+import (
+	"testing"
+)
+
+func TestScheduleValueAt(t *testing.T) {
+	sine := &Schedule{Param: "reseed", Kind: SINE_SCHEDULE, Mean: 50, Amplitude: 40, Period: 100}
+	step := &Schedule{Param: "reseed", Kind: STEP_SCHEDULE, Points: []SchedulePoint{{100, 10}, {200, 90}}}
+	table := &Schedule{Param: "reseed", Kind: TABLE_SCHEDULE, Points: []SchedulePoint{{100, 10}, {200, 90}}, Repeat: 300}
+
+	tests := []struct {
+		schedule *Schedule
+		cycle    int
+		expected int
+	}{
+		{sine, 0, 50},
+		{sine, 25, 90},
+		{sine, 75, 10},
+		{sine, 125, 90},
+		{step, 0, 10},
+		{step, 150, 10},
+		{step, 200, 90},
+		{step, 5000, 90},
+		{table, 50, 10},
+		{table, 150, 50},
+		{table, 250, 90},
+		{table, 450, 50},
+	}
+	for _, test := range tests {
+		if v := test.schedule.ValueAt(test.cycle); v != test.expected {
+			t.Errorf("%s at cycle %d: expected %d, got %d", test.schedule, test.cycle, test.expected, v)
+		}
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	for _, input := range []string{"reseed sine 50 40 1000 250", "capacity step 0:10 500:30", "bacterium-energy table 0:40 1000:10 repeat 2000"} {
+		s, err := ParseSchedule(input)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", input, err)
+			continue
+		}
+		if s.String() != input {
+			t.Errorf("%q: String() did not round trip, got %q", input, s.String())
+		}
+	}
+
+	for _, input := range []string{"", "reseed", "heat sine 1 1 1", "reseed sine 1 1 0", "reseed wave 1 1 1", "reseed step 10:1 5:2", "reseed step 10", "reseed table repeat 5"} {
+		if _, err := ParseSchedule(input); err == nil {
+			t.Errorf("%q: expected an error", input)
+		}
+	}
+}
+
+func TestSchedulesApplyEachCycle(t *testing.T) {
+	w := NewGameWorld(50, 50)
+	w.Seed = 1
+	w.InitialBugCount = 0
+	w.Rules.HistoryInterval = 1
+	w.Schedules = []*Schedule{
+		{Param: "reseed", Kind: STEP_SCHEDULE, Points: []SchedulePoint{{0, 0}, {3, 200}}},
+		{Param: "capacity", Kind: STEP_SCHEDULE, Points: []SchedulePoint{{0, 500}}},
+	}
+	w.Initialize()
+
+	w.Next()
+	w.Next()
+	reseed := w.ReseedRate()
+	capacity := w.scheduled(CAPACITY_PARAM, w.Growth.CarryingCapacity)
+	if reseed != 0 || capacity != 100 {
+		t.Errorf("expected reseed 0 and capacity held at 100, got %d and %d", reseed, capacity)
+	}
+	w.Next()
+	if reseed := w.ReseedRate(); reseed != 200 {
+		t.Errorf("expected reseed 200 from cycle 3, got %d", reseed)
+	}
+	if w.ReseedBacteria != 10 || w.Growth.CarryingCapacity != DefaultGrowth().CarryingCapacity {
+		t.Errorf("expected the schedules to leave the settings alone, got reseed %d and capacity %d",
+			w.ReseedBacteria, w.Growth.CarryingCapacity)
+	}
+	if energy := w.scheduled(BACTERIUM_ENERGY_PARAM, w.Rules.EnergyPerBacterium); energy != w.Rules.EnergyPerBacterium {
+		t.Errorf("expected an unscheduled parameter to keep its setting, got %d", energy)
+	}
+
+	history := w.History()
+	if env := history[len(history)-1].Environment; env["reseed"] != 200 || env["capacity"] != 100 {
+		t.Errorf("expected the history to record the scheduled values, got %v", env)
+	}
+}
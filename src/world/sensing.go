@@ -0,0 +1,53 @@
+package world
+
+import (
+	"math/rand/v2"
+)
+
+// SENSE_DISTANCE is how many cells ahead of a bug the patches it senses are
+// centred on.
+const SENSE_DISTANCE = 2
+
+// SENSE_GENES is the number of sense genes a sensing bug carries, one each
+// for the food ahead, to its left and to its right.
+const SENSE_GENES = 3
+
+// newSenseGenes gives a bug random sense genes, in the same range as its
+// turn genes start in.
+func newSenseGenes(rng *rand.Rand, b *Bug) {
+	b.senseGene = make([]int, SENSE_GENES)
+	for i := range b.senseGene {
+		b.senseGene[i] = rng.IntN(4) - 2
+	}
+}
+
+// sense counts the food in the 3x3 patches SENSE_DISTANCE cells ahead of a
+// bug, ahead and to its left, and ahead and to its right, wrapping around
+// the edges of a torus. Only bugs with sense genes or a NetworkBrain
+// sense. Only food a bug gains energy from is counted, so a bug whose genes
+// draw it towards food steers clear of toxins.
+func (w *GameWorld) sense(b *Bug) {
+	if _, ok := b.brain.(*NetworkBrain); !ok && len(b.senseGene) == 0 {
+		return
+	}
+
+	n := len(w.geometry.Directions)
+	headings := []int{b.direction, (b.direction + 1) % n, (b.direction + n - 1) % n}
+	if len(b.sensed) != len(headings) {
+		b.sensed = make([]int, len(headings))
+	}
+
+	for i, heading := range headings {
+		offset := w.geometry.Directions[heading]
+		cx := b.X + offset.X*SENSE_DISTANCE
+		cy := b.Y + offset.Y*SENSE_DISTANCE
+
+		count := 0
+		for _, p := range w.Neighbors(cx, cy, 1) {
+			if v := w.cells[p.Y*w.Width+p.X]; v != 0 && w.foodEnergy(b, v) > 0 {
+				count++
+			}
+		}
+		b.sensed[i] = count
+	}
+}
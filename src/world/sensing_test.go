@@ -0,0 +1,80 @@
+package world
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSenseCountsFoodAroundBug(t *testing.T) {
+	w := NewGameWorld(20, 20)
+	w.InitialBacteria = 0
+	w.InitialBugCount = 0
+	w.Geometry = Square4Geometry()
+	w.FoodTypes = []*FoodType{{Name: "toxin", Color: "purple", Energy: -60}}
+	w.Initialize()
+
+	b := bugWithGenes(0, 0, 0, 0)
+	b.X, b.Y = 10, 18
+	b.senseGene = []int{1, 1, 1}
+
+	// Ahead of the bug wraps around to the top rows of the world.
+	w.SetCell(10, 2, 1)
+	w.SetCell(11, 3, 1)
+	// To its left, one bacterium and one toxin, which is not counted.
+	w.SetCell(14, 18, 1)
+	w.SetCell(14, 19, 2)
+	// Just outside the patch to its right.
+	w.SetCell(4, 18, 1)
+
+	w.sense(b)
+	if expected := []int{2, 1, 0}; !slices.Equal(b.sensed, expected) {
+		t.Errorf("expected to sense %v, got %v", expected, b.sensed)
+	}
+}
+
+func TestSenseGenesWeightTurns(t *testing.T) {
+	b := bugWithGenes(2, 0, 0, 0)
+	b.senseGene = []int{-1, 3, 0}
+	b.sensed = []int{2, 4, 9}
+
+	weights, total := b.turnWeights()
+	if expected := []int{2, 12, 0, 0}; !slices.Equal(weights, expected) || total != 14 {
+		t.Errorf("expected weights %v totalling 14, got %v totalling %d", expected, weights, total)
+	}
+
+	probabilities := b.TurnProbabilities()
+	if probabilities[1] <= probabilities[0] {
+		t.Errorf("expected sensed food to favour turning left, got %v", probabilities)
+	}
+}
+
+func TestSensingGenesAreInherited(t *testing.T) {
+	w := NewGameWorld(50, 50)
+	w.Seed = 5
+	w.Initialize()
+	for _, b := range w.bugs {
+		if b.SenseGenes() != nil {
+			t.Fatalf("expected no sense genes without sensing, got %v", b.SenseGenes())
+		}
+	}
+
+	w.Sensing = true
+	w.Initialize()
+	parent := w.bugs[0]
+	if len(parent.SenseGenes()) != SENSE_GENES {
+		t.Fatalf("expected %d sense genes, got %v", SENSE_GENES, parent.SenseGenes())
+	}
+
+	child := parent.NewBugFrom()
+	if !slices.Equal(child.SenseGenes(), parent.SenseGenes()) {
+		t.Errorf("expected child to inherit sense genes %v, got %v", parent.SenseGenes(), child.SenseGenes())
+	}
+
+	// Mutating every gene must reach the sense genes too.
+	MutationPolicy{GeneRate: 1}.Apply(w.rng, child, 1)
+	for i, v := range child.SenseGenes() {
+		if v == parent.SenseGenes()[i] {
+			t.Errorf("expected sense gene %d to mutate, still %d", i, v)
+		}
+	}
+}
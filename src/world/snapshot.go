@@ -0,0 +1,405 @@
+package world
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+)
+
+// SnapshotVersion is the version of the snapshot format written by
+// MarshalSnapshot. Bump it whenever the format changes in a way older
+// readers cannot understand.
+const SnapshotVersion = 2
+
+type bugSnapshot struct {
+	ID             int    `json:"id"`
+	ParentID       int    `json:"parentId,omitempty"`
+	MateID         int    `json:"mateId,omitempty"`
+	X              int    `json:"x"`
+	Y              int    `json:"y"`
+	Age            int    `json:"age"`
+	Energy         int    `json:"energy"`
+	Classification string `json:"classification"`
+	FoodPreference int    `json:"foodPreference,omitempty"`
+	Direction      int    `json:"direction"`
+	GeneValue      []int  `json:"geneValue"`
+	GeneWeight     []int  `json:"geneWeight"`
+	SenseGene      []int  `json:"senseGene,omitempty"`
+	Sensed         []int  `json:"sensed,omitempty"`
+	Brain          string `json:"brain,omitempty"`
+	BrainGenes     []int  `json:"brainGenes,omitempty"`
+}
+
+type regionSnapshot struct {
+	FertilityRegion
+	ReseedTotal int `json:"reseedTotal"`
+}
+
+type foodSnapshot struct {
+	FoodType
+	ReseedTotal int `json:"reseedTotal"`
+}
+
+type worldSnapshot struct {
+	Version int `json:"version"`
+
+	Width                int            `json:"width"`
+	Height               int            `json:"height"`
+	InitialBacteria      int            `json:"initialBacteria"`
+	ReseedBacteria       int            `json:"reseedBacteria"`
+	InitialBugCount      int            `json:"initialBugCount"`
+	InitialPredatorCount int            `json:"initialPredatorCount,omitempty"`
+	Seed                 uint64         `json:"seed"`
+	KeepFullHistory      bool           `json:"keepFullHistory,omitempty"`
+	RecordLineage        bool           `json:"recordLineage,omitempty"`
+	SexualReproduction   bool           `json:"sexualReproduction,omitempty"`
+	Rules                Rules          `json:"rules"`
+	Mutation             MutationPolicy `json:"mutation"`
+	Geometry             Geometry       `json:"geometry"`
+	Classifier           string         `json:"classifier"`
+	Centroids            [][]float64    `json:"centroids,omitempty"` // the k-means classifier's clusters
+
+	FertilityRegions []regionSnapshot `json:"fertilityRegions,omitempty"`
+	FertilityMap     *FertilityMap    `json:"fertilityMap,omitempty"`
+	Terrain          *Terrain         `json:"terrain,omitempty"`
+	Boundary         Boundary         `json:"boundary,omitempty"`
+	FoodTypes        []foodSnapshot   `json:"foodTypes,omitempty"`
+	FoodPreferences  bool             `json:"foodPreferences,omitempty"`
+	Sensing          bool             `json:"sensing,omitempty"`
+	NetworkBrains    int              `json:"networkBrains,omitempty"`
+	Growth           Growth           `json:"growth"`
+	Schedules        []*Schedule      `json:"schedules,omitempty"`
+	Competition      Competition      `json:"competition"`
+	Lineage          []*LineageRecord `json:"lineage,omitempty"`
+
+	Cycle       int            `json:"cycle"`
+	ReseedTotal int            `json:"reseedTotal"`
+	LastBugID   int            `json:"lastBugId"`
+	Cells       []byte         `json:"cells"`
+	Bugs        []bugSnapshot  `json:"bugs"`
+	Predators   []bugSnapshot  `json:"predators,omitempty"`
+	History     []HistoryEntry `json:"history"`
+	RNG         []byte         `json:"rng"`
+}
+
+// MarshalSnapshot captures the complete state of the world, including the
+// random number generator, so that a run can be resumed exactly where it
+// left off.
+func (w *GameWorld) MarshalSnapshot() ([]byte, error) {
+	if w.pcg == nil {
+		return nil, fmt.Errorf("world has not been initialized")
+	}
+
+	rng, err := w.pcg.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := worldSnapshot{
+		Version:              SnapshotVersion,
+		Width:                w.Width,
+		Height:               w.Height,
+		InitialBacteria:      w.InitialBacteria,
+		ReseedBacteria:       w.ReseedBacteria,
+		InitialBugCount:      w.InitialBugCount,
+		InitialPredatorCount: w.InitialPredatorCount,
+		Seed:                 w.Seed,
+		KeepFullHistory:      w.KeepFullHistory,
+		RecordLineage:        w.RecordLineage,
+		SexualReproduction:   w.SexualReproduction,
+		Rules:                w.Rules,
+		Mutation:             w.Mutation,
+		Geometry:             w.geometry,
+		Classifier:           w.classifier.Name(),
+		Cycle:                w.cycle,
+		ReseedTotal:          w.reseedTotal,
+		LastBugID:            w.lastBugID,
+		Cells:                w.cells,
+		Bugs:                 snapshotBugs(w.bugs),
+		Predators:            snapshotBugs(w.predators),
+		History:              w.history,
+		RNG:                  rng,
+		FertilityMap:         w.FertilityMap,
+		Terrain:              w.Terrain,
+		Boundary:             w.Boundary,
+		FoodPreferences:      w.FoodPreferences,
+		Sensing:              w.Sensing,
+		NetworkBrains:        w.NetworkBrains,
+		Growth:               w.Growth,
+		Schedules:            w.Schedules,
+		Competition:          w.Competition,
+	}
+
+	if w.lineage != nil {
+		snapshot.Lineage = w.lineage.Records()
+	}
+	if kmeans, ok := w.classifier.(*KMeansClassifier); ok {
+		snapshot.Centroids = kmeans.centroids
+	}
+
+	for _, f := range w.FoodTypes {
+		snapshot.FoodTypes = append(snapshot.FoodTypes, foodSnapshot{
+			FoodType:    *f,
+			ReseedTotal: f.reseedTotal,
+		})
+	}
+
+	for _, r := range w.FertilityRegions {
+		snapshot.FertilityRegions = append(snapshot.FertilityRegions, regionSnapshot{
+			FertilityRegion: *r,
+			ReseedTotal:     r.reseedTotal,
+		})
+	}
+
+	return json.Marshal(snapshot)
+}
+
+// UnmarshalSnapshot replaces the state of the world with a snapshot
+// previously produced by MarshalSnapshot. The world is left untouched if the
+// snapshot cannot be read.
+func (w *GameWorld) UnmarshalSnapshot(data []byte) error {
+	// Settings missing from the snapshot keep their defaults
+	snapshot := worldSnapshot{Rules: DefaultRules(), Geometry: HexGeometry(), Classifier: FORWARD_CLASSIFIER, Growth: DefaultGrowth()}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return err
+	}
+
+	if snapshot.Version != SnapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", snapshot.Version)
+	}
+	if snapshot.Width <= 0 || snapshot.Height <= 0 {
+		return fmt.Errorf("invalid snapshot size %d x %d", snapshot.Width, snapshot.Height)
+	}
+	if len(snapshot.Cells) != snapshot.Width*snapshot.Height {
+		return fmt.Errorf("snapshot has %d cells, expected %d", len(snapshot.Cells), snapshot.Width*snapshot.Height)
+	}
+
+	if err := snapshot.Growth.Validate(); err != nil {
+		return err
+	}
+	if err := snapshot.Competition.Validate(); err != nil {
+		return err
+	}
+	for _, s := range snapshot.Schedules {
+		if err := s.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := snapshot.Mutation.Validate(); err != nil {
+		return err
+	}
+	if snapshot.NetworkBrains < 0 || snapshot.NetworkBrains > 100 {
+		return fmt.Errorf("network brain percentage must be between 0 and 100")
+	}
+	if err := snapshot.Rules.Validate(); err != nil {
+		return err
+	}
+	if err := snapshot.Geometry.Validate(); err != nil {
+		return err
+	}
+	classifier, err := ClassifierByName(snapshot.Classifier)
+	if err != nil {
+		return err
+	}
+	if kmeans, ok := classifier.(*KMeansClassifier); ok && len(snapshot.Centroids) > 0 {
+		if len(snapshot.Centroids) != kmeans.K {
+			return fmt.Errorf("snapshot has %d centroids, expected %d", len(snapshot.Centroids), kmeans.K)
+		}
+		for _, centroid := range snapshot.Centroids {
+			if len(centroid) != snapshot.Geometry.GenomeLength() {
+				return fmt.Errorf("centroid has %d genes, expected %d", len(centroid), snapshot.Geometry.GenomeLength())
+			}
+		}
+		kmeans.centroids = snapshot.Centroids
+	}
+
+	regions := make([]*FertilityRegion, 0, len(snapshot.FertilityRegions))
+	for _, s := range snapshot.FertilityRegions {
+		r := s.FertilityRegion
+		if err := r.Validate(); err != nil {
+			return err
+		}
+		r.reseedTotal = s.ReseedTotal
+		regions = append(regions, &r)
+	}
+
+	foods := make([]*FoodType, 0, len(snapshot.FoodTypes))
+	for _, s := range snapshot.FoodTypes {
+		f := s.FoodType
+		f.reseedTotal = s.ReseedTotal
+		foods = append(foods, &f)
+	}
+	if err := ValidateFoodTypes(foods); err != nil {
+		return err
+	}
+	for _, v := range snapshot.Cells {
+		if int(v) > len(foods)+1 {
+			return fmt.Errorf("snapshot has a cell holding unknown food %d", v)
+		}
+	}
+
+	if snapshot.FertilityMap != nil {
+		if err := snapshot.FertilityMap.Validate(); err != nil {
+			return err
+		}
+	}
+	if snapshot.Terrain != nil {
+		if err := snapshot.Terrain.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := snapshot.Boundary.Validate(); err != nil {
+		return err
+	}
+
+	var lineage *Lineage
+	if snapshot.RecordLineage {
+		lineage = NewLineage()
+		for _, r := range snapshot.Lineage {
+			lineage.add(r)
+		}
+	}
+
+	pcg := &rand.PCG{}
+	if err := pcg.UnmarshalBinary(snapshot.RNG); err != nil {
+		return err
+	}
+
+	bugs, err := restoreBugs(snapshot.Bugs, snapshot.Geometry, snapshot.Width, snapshot.Height)
+	if err != nil {
+		return err
+	}
+	predators, err := restoreBugs(snapshot.Predators, snapshot.Geometry, snapshot.Width, snapshot.Height)
+	if err != nil {
+		return err
+	}
+	for _, b := range bugs {
+		if b.FoodPreference < 0 || b.FoodPreference > len(foods) {
+			return fmt.Errorf("bug %d prefers unknown food %d", b.ID, b.FoodPreference)
+		}
+	}
+
+	w.Width = snapshot.Width
+	w.Height = snapshot.Height
+	w.InitialBacteria = snapshot.InitialBacteria
+	w.ReseedBacteria = snapshot.ReseedBacteria
+	w.InitialBugCount = snapshot.InitialBugCount
+	w.InitialPredatorCount = snapshot.InitialPredatorCount
+	w.Seed = snapshot.Seed
+	w.KeepFullHistory = snapshot.KeepFullHistory
+	w.RecordLineage = snapshot.RecordLineage
+	w.SexualReproduction = snapshot.SexualReproduction
+	w.lineage = lineage
+	w.Rules = snapshot.Rules
+	w.Mutation = snapshot.Mutation
+	w.Geometry = snapshot.Geometry
+	w.geometry = snapshot.Geometry
+	w.FertilityRegions = regions
+	w.FertilityMap = snapshot.FertilityMap
+	w.Terrain = snapshot.Terrain
+	w.Boundary = snapshot.Boundary
+	w.FoodTypes = foods
+	w.FoodPreferences = snapshot.FoodPreferences
+	w.Sensing = snapshot.Sensing
+	w.NetworkBrains = snapshot.NetworkBrains
+	w.Growth = snapshot.Growth
+	w.Schedules = snapshot.Schedules
+	w.Competition = snapshot.Competition
+	w.cycle = snapshot.Cycle
+	w.reseedTotal = snapshot.ReseedTotal
+	w.lastBugID = snapshot.LastBugID
+	w.followedID = 0
+	w.cells = snapshot.Cells
+	w.recountFood()
+	w.bugs = bugs
+	w.predators = predators
+	w.classifier = classifier
+	w.geneHistogram = NewGeneHistogram(bugs)
+	w.history = snapshot.History
+	if w.history == nil {
+		w.history = []HistoryEntry{}
+	}
+	w.pcg = pcg
+	w.rng = rand.New(pcg)
+
+	return nil
+}
+
+func snapshotBugs(bugs []*Bug) []bugSnapshot {
+	result := make([]bugSnapshot, 0, len(bugs))
+	for _, b := range bugs {
+		var brain string
+		var brainGenes []int
+		if network, ok := b.brain.(*NetworkBrain); ok {
+			brain = network.Name()
+			brainGenes = network.weights
+		}
+		result = append(result, bugSnapshot{
+			ID:             b.ID,
+			ParentID:       b.ParentID,
+			MateID:         b.MateID,
+			X:              b.X,
+			Y:              b.Y,
+			Age:            b.Age,
+			Energy:         b.Energy,
+			Classification: b.Classification,
+			FoodPreference: b.FoodPreference,
+			Direction:      b.direction,
+			GeneValue:      b.geneValue,
+			GeneWeight:     b.geneWeight,
+			SenseGene:      b.senseGene,
+			Sensed:         b.sensed,
+			Brain:          brain,
+			BrainGenes:     brainGenes,
+		})
+	}
+	return result
+}
+
+func restoreBugs(snapshots []bugSnapshot, geometry Geometry, width, height int) ([]*Bug, error) {
+	result := make([]*Bug, 0, len(snapshots))
+	for _, s := range snapshots {
+		if s.X < 0 || s.X >= width || s.Y < 0 || s.Y >= height {
+			return nil, fmt.Errorf("bug %d is at %d, %d, outside the %d x %d world", s.ID, s.X, s.Y, width, height)
+		}
+		if len(s.GeneValue) != geometry.GenomeLength() {
+			return nil, fmt.Errorf("bug %d has %d genes, expected %d", s.ID, len(s.GeneValue), geometry.GenomeLength())
+		}
+		if s.Direction < 0 || s.Direction >= geometry.GenomeLength() {
+			return nil, fmt.Errorf("bug %d has invalid direction %d", s.ID, s.Direction)
+		}
+		if len(s.SenseGene) != 0 && len(s.SenseGene) != SENSE_GENES {
+			return nil, fmt.Errorf("bug %d has %d sense genes, expected %d", s.ID, len(s.SenseGene), SENSE_GENES)
+		}
+		if len(s.Sensed) != 0 && len(s.Sensed) != SENSE_GENES {
+			return nil, fmt.Errorf("bug %d sensed %d patches, expected %d", s.ID, len(s.Sensed), SENSE_GENES)
+		}
+		brain, err := restoreBrain(s.Brain, s.BrainGenes, geometry.GenomeLength())
+		if err != nil {
+			return nil, fmt.Errorf("bug %d: %v", s.ID, err)
+		}
+
+		b := &Bug{
+			ID:             s.ID,
+			ParentID:       s.ParentID,
+			MateID:         s.MateID,
+			X:              s.X,
+			Y:              s.Y,
+			Age:            s.Age,
+			Energy:         s.Energy,
+			FoodPreference: s.FoodPreference,
+			direction:      s.Direction,
+			geneValue:      s.GeneValue,
+			senseGene:      s.SenseGene,
+			sensed:         s.Sensed,
+			brain:          brain,
+		}
+		// The weights are always the squares of the values, so rebuild them
+		// rather than trust the file, where a negative weight would break
+		// turning.
+		b.updateWeights()
+		b.Classification = s.Classification
+		result = append(result, b)
+	}
+	return result, nil
+}
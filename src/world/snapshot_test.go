@@ -0,0 +1,163 @@
+package world
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestSnapshotRoundTripResumesIdentically(t *testing.T) {
+	original := NewGameWorld(100, 100)
+	original.Seed = 99
+	original.Initialize()
+	for range 500 {
+		original.Next()
+	}
+
+	data, err := original.MarshalSnapshot()
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+
+	restored := NewGameWorld(10, 10)
+	if err := restored.UnmarshalSnapshot(data); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	for range 1000 {
+		original.Next()
+		restored.Next()
+	}
+
+	if restored.Cycle() != original.Cycle() {
+		t.Fatalf("expected cycle %d, got %d", original.Cycle(), restored.Cycle())
+	}
+	a, b := original.History(), restored.History()
+	if len(a) != len(b) {
+		t.Fatalf("expected %d history entries, got %d", len(a), len(b))
+	}
+	for i := range a {
+		if !reflect.DeepEqual(a[i], b[i]) {
+			t.Fatalf("history differs at entry %d: %+v vs %+v", i, a[i], b[i])
+		}
+	}
+}
+
+func TestUnmarshalSnapshotRejectsUnknownVersion(t *testing.T) {
+	w := NewGameWorld(10, 10)
+	if err := w.UnmarshalSnapshot([]byte(`{"version": 999}`)); err == nil {
+		t.Error("expected an error for an unknown snapshot version")
+	}
+}
+
+func TestUnmarshalSnapshotRejectsBugsOutsideTheWorld(t *testing.T) {
+	w := NewGameWorld(10, 10)
+	w.Initialize()
+	data, err := w.MarshalSnapshot()
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+
+	var snapshot worldSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		t.Fatalf("decoding the snapshot failed: %v", err)
+	}
+	snapshot.Bugs[0].X = 10
+	if data, err = json.Marshal(snapshot); err != nil {
+		t.Fatalf("encoding the snapshot failed: %v", err)
+	}
+
+	if err := NewGameWorld(10, 10).UnmarshalSnapshot(data); err == nil {
+		t.Error("expected an error for a bug outside the world")
+	}
+}
+
+func TestUnmarshalSnapshotRebuildsGeneWeights(t *testing.T) {
+	w := NewGameWorld(10, 10)
+	w.Initialize()
+	data, err := w.MarshalSnapshot()
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+
+	var snapshot worldSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		t.Fatalf("decoding the snapshot failed: %v", err)
+	}
+	for i := range snapshot.Bugs {
+		for j := range snapshot.Bugs[i].GeneWeight {
+			snapshot.Bugs[i].GeneWeight[j] = -1
+		}
+	}
+	if data, err = json.Marshal(snapshot); err != nil {
+		t.Fatalf("encoding the snapshot failed: %v", err)
+	}
+
+	restored := NewGameWorld(10, 10)
+	if err := restored.UnmarshalSnapshot(data); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	for _, b := range restored.Bugs() {
+		for i, v := range b.geneValue {
+			if b.geneWeight[i] != v*v {
+				t.Fatalf("bug %d gene %d has weight %d, expected %d", b.ID, i, b.geneWeight[i], v*v)
+			}
+		}
+	}
+	restored.Next()
+}
+
+func TestSnapshotKeepsSensedFood(t *testing.T) {
+	original := NewGameWorld(50, 50)
+	original.Seed = 5
+	original.Sensing = true
+	original.Initialize()
+	for range 10 {
+		original.Next()
+	}
+
+	data, err := original.MarshalSnapshot()
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	restored := NewGameWorld(10, 10)
+	if err := restored.UnmarshalSnapshot(data); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	for i, b := range restored.Bugs() {
+		if want := original.Bugs()[i].Sensed(); !slices.Equal(b.Sensed(), want) {
+			t.Fatalf("bug %d sensed %v, expected %v", b.ID, b.Sensed(), want)
+		}
+	}
+}
+
+func TestSnapshotKeepsKMeansCentroids(t *testing.T) {
+	original := NewGameWorld(100, 100)
+	original.Seed = 99
+	original.SetClassifier(NewKMeansClassifier(4))
+	original.Initialize()
+	for range 500 {
+		original.Next()
+	}
+
+	data, err := original.MarshalSnapshot()
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	restored := NewGameWorld(10, 10)
+	if err := restored.UnmarshalSnapshot(data); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	for range 200 {
+		original.Next()
+		restored.Next()
+	}
+	a := original.Classifier().(*KMeansClassifier).centroids
+	b := restored.Classifier().(*KMeansClassifier).centroids
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("expected the restored centroids to follow the original, got %v and %v", b, a)
+	}
+}
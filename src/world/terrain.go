@@ -0,0 +1,239 @@
+package world
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+type Boundary int
+
+const (
+	TORUS_BOUNDARY   Boundary = iota // bugs leaving one edge come back on the opposite edge, the original behaviour
+	REFLECT_BOUNDARY                 // the edges are walls that bugs bounce off
+	ABSORB_BOUNDARY                  // bugs leaving the world die
+)
+
+// ParseBoundary reads a boundary written as "torus", "reflect" or "absorb".
+func ParseBoundary(s string) (Boundary, error) {
+	for _, b := range []Boundary{TORUS_BOUNDARY, REFLECT_BOUNDARY, ABSORB_BOUNDARY} {
+		if b.String() == s {
+			return b, nil
+		}
+	}
+	return TORUS_BOUNDARY, fmt.Errorf("unknown boundary %q", s)
+}
+
+func (b Boundary) String() string {
+	switch b {
+	case REFLECT_BOUNDARY:
+		return "reflect"
+	case ABSORB_BOUNDARY:
+		return "absorb"
+	}
+	return "torus"
+}
+
+func (b Boundary) Validate() error {
+	if b < TORUS_BOUNDARY || b > ABSORB_BOUNDARY {
+		return fmt.Errorf("unknown boundary %d", b)
+	}
+	return nil
+}
+
+// Terrain marks the cells of the world bugs cannot enter and bacteria cannot
+// grow on. Like a FertilityMap, it is stretched over the world, so it does
+// not need to be the same size.
+type Terrain struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Walls  []byte `json:"walls"` // 1 for a wall, 0 for open ground
+}
+
+// NewTerrain returns a terrain of the given size with no walls, ready to
+// have walls drawn on it.
+func NewTerrain(width, height int) *Terrain {
+	return &Terrain{
+		Width:  width,
+		Height: height,
+		Walls:  make([]byte, width*height),
+	}
+}
+
+// NewTerrainFromImage builds a terrain from an image, where dark pixels are
+// walls and light pixels are open ground, so a maze can be drawn in black on
+// white.
+func NewTerrainFromImage(img image.Image) *Terrain {
+	bounds := img.Bounds()
+	result := NewTerrain(bounds.Dx(), bounds.Dy())
+
+	for y := range result.Height {
+		for x := range result.Width {
+			gray := color.GrayModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray)
+			if gray.Y < 128 {
+				result.Walls[y*result.Width+x] = 1
+			}
+		}
+	}
+
+	return result
+}
+
+// LoadTerrain reads a terrain from a PNG image.
+func LoadTerrain(r io.Reader) (*Terrain, error) {
+	img, err := png.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTerrainFromImage(img), nil
+}
+
+func (t *Terrain) Validate() error {
+	if t.Width <= 0 || t.Height <= 0 {
+		return fmt.Errorf("invalid terrain size %d x %d", t.Width, t.Height)
+	}
+	if len(t.Walls) != t.Width*t.Height {
+		return fmt.Errorf("terrain has %d cells, expected %d", len(t.Walls), t.Width*t.Height)
+	}
+	for _, v := range t.Walls {
+		if v == 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("terrain has no open ground")
+}
+
+// Wall reports whether the world cell x, y is a wall, for a world of the
+// given size.
+func (t *Terrain) Wall(x, y, worldWidth, worldHeight int) bool {
+	return t.Walls[t.index(x, y, worldWidth, worldHeight)] != 0
+}
+
+// SetWall builds or knocks down the wall covering the world cell x, y, for a
+// world of the given size.
+func (t *Terrain) SetWall(x, y, worldWidth, worldHeight int, wall bool) {
+	var v byte
+	if wall {
+		v = 1
+	}
+	t.Walls[t.index(x, y, worldWidth, worldHeight)] = v
+}
+
+func (t *Terrain) index(x, y, worldWidth, worldHeight int) int {
+	tx := x * t.Width / worldWidth
+	ty := y * t.Height / worldHeight
+	return ty*t.Width + tx
+}
+
+// isWall reports whether x, y is a wall of the world's terrain.
+func (w *GameWorld) isWall(x, y int) bool {
+	return w.Terrain != nil && w.Terrain.Wall(x, y, w.Width, w.Height)
+}
+
+// walls returns a blocked func for moving b that keeps it from stepping into
+// or through a wall, and nil for a world of open ground.
+func (w *GameWorld) walls(b *Bug) func(x, y int) bool {
+	if w.Terrain == nil {
+		return nil
+	}
+	fromX, fromY := b.X, b.Y
+	return func(x, y int) bool {
+		return w.crossesWall(fromX, fromY, x, y)
+	}
+}
+
+// crossesWall reports whether a step from x1, y1 to x2, y2 passes through or
+// ends on a wall, taking the short way round the edges of a torus. Bugs step
+// more than one cell at a time, so every cell along the way is checked, and
+// a step cutting between two cells passes through both of them.
+func (w *GameWorld) crossesWall(x1, y1, x2, y2 int) bool {
+	dx, dy := x2-x1, y2-y1
+	if w.Boundary == TORUS_BOUNDARY {
+		dx = shortest(dx, w.Width)
+		dy = shortest(dy, w.Height)
+	}
+
+	steps := max(abs(dx), abs(dy))
+	for i := 1; i <= steps; i++ {
+		for _, x := range between(x1, dx*i, steps) {
+			for _, y := range between(y1, dy*i, steps) {
+				if x, y, ok := w.wrap(x, y); ok && w.isWall(x, y) {
+					return true
+				}
+			}
+		}
+	}
+	return w.isWall(x2, y2)
+}
+
+// shortest turns a distance d along an axis of the given size into the
+// shortest distance across the edges of a torus.
+func shortest(d, size int) int {
+	if d > size/2 {
+		return d - size
+	}
+	if d < -size/2 {
+		return d + size
+	}
+	return d
+}
+
+// between returns the cells either side of the point c + d/steps, or just
+// the one cell when the point falls on it.
+func between(c, d, steps int) []int {
+	lo := d / steps
+	if d%steps == 0 {
+		return []int{c + lo}
+	}
+	if d < 0 {
+		lo--
+	}
+	return []int{c + lo, c + lo + 1}
+}
+
+// wrap brings x, y back inside the world across the edges of a torus. It
+// reports false for a point outside the world with any other boundary.
+func (w *GameWorld) wrap(x, y int) (int, int, bool) {
+	if x >= 0 && x < w.Width && y >= 0 && y < w.Height {
+		return x, y, true
+	}
+	if w.Boundary != TORUS_BOUNDARY {
+		return x, y, false
+	}
+	return (x%w.Width + w.Width) % w.Width, (y%w.Height + w.Height) % w.Height, true
+}
+
+// randomOpenCell picks a random cell that is not a wall, settling for the
+// last cell tried if it cannot find one in as many attempts as there are
+// cells.
+func (w *GameWorld) randomOpenCell() (int, int) {
+	var x, y int
+	for range len(w.cells) {
+		x = w.rng.IntN(w.Width)
+		y = w.rng.IntN(w.Height)
+		if !w.isWall(x, y) {
+			break
+		}
+	}
+	return x, y
+}
+
+// SetWall builds or knocks down a wall at the world cell x, y, first giving
+// the world an open terrain of its own size if it has none. Any food on the
+// cell is cleared away when it becomes a wall.
+func (w *GameWorld) SetWall(x, y int, wall bool) {
+	if x < 0 || y < 0 || x >= w.Width || y >= w.Height {
+		return
+	}
+	if w.Terrain == nil {
+		w.Terrain = NewTerrain(w.Width, w.Height)
+	}
+
+	w.Terrain.SetWall(x, y, w.Width, w.Height, wall)
+	if wall {
+		w.clearFood(y*w.Width + x)
+	}
+}
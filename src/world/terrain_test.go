@@ -0,0 +1,183 @@
+package world
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestParseBoundary(t *testing.T) {
+	for _, b := range []Boundary{TORUS_BOUNDARY, REFLECT_BOUNDARY, ABSORB_BOUNDARY} {
+		parsed, err := ParseBoundary(b.String())
+		if err != nil || parsed != b {
+			t.Errorf("expected %s to parse back, got %v, %v", b, parsed, err)
+		}
+	}
+	if _, err := ParseBoundary("sphere"); err == nil {
+		t.Errorf("expected an unknown boundary to be rejected")
+	}
+}
+
+func TestTerrainFromImage(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.Gray{Y: 255})
+	img.Set(1, 0, color.Gray{Y: 0})
+	img.Set(0, 1, color.Gray{Y: 200})
+	img.Set(1, 1, color.Gray{Y: 50})
+	terrain := NewTerrainFromImage(img)
+
+	tests := []struct {
+		x, y int
+		wall bool
+	}{
+		{0, 0, false},
+		{9, 9, false},
+		{10, 0, true},
+		{19, 9, true},
+		{5, 15, false},
+		{15, 15, true},
+	}
+	for _, test := range tests {
+		if got := terrain.Wall(test.x, test.y, 20, 20); got != test.wall {
+			t.Errorf("expected wall at %d, %d to be %v, got %v", test.x, test.y, test.wall, got)
+		}
+	}
+
+	if err := NewTerrainFromImage(image.NewGray(image.Rect(0, 0, 2, 2))).Validate(); err == nil {
+		t.Errorf("expected a terrain with no open ground to be rejected")
+	}
+}
+
+func TestWallsBlockBugsAndFood(t *testing.T) {
+	w := NewGameWorld(20, 20)
+	w.InitialBacteria = 100
+	w.InitialBugCount = 0
+	w.ReseedBacteria = 500
+	w.Terrain = NewTerrain(2, 1)
+	w.Terrain.Walls[1] = 1 // the right half of the world
+	w.Initialize()
+
+	offset := w.ActiveGeometry().Directions[1]
+	b := bugWithGenes(0, 2, 0, 0, 0, 0) // always turns once
+	b.X, b.Y, b.Energy = 10-offset.X, 10-offset.Y, 100
+	b.direction = 0
+	w.bugs = []*Bug{b}
+	for range 10 {
+		w.Next()
+	}
+	for _, b := range w.Bugs() {
+		if w.isWall(b.X, b.Y) {
+			t.Errorf("bug moved into a wall at %d, %d", b.X, b.Y)
+		}
+	}
+
+	for y := range w.Height {
+		for x := range w.Width {
+			if v, _ := w.GetCell(x, y); v != 0 && w.isWall(x, y) {
+				t.Fatalf("food grew on the wall at %d, %d", x, y)
+			}
+		}
+	}
+}
+
+func TestBoundaries(t *testing.T) {
+	tests := []struct {
+		boundary  Boundary
+		x, y      int
+		direction int
+		alive     bool
+	}{
+		{TORUS_BOUNDARY, 10, 19, 3, true},  // comes back in at the bottom
+		{REFLECT_BOUNDARY, 10, 3, 0, true}, // bounces back down
+		{ABSORB_BOUNDARY, 10, 1, 3, false},
+	}
+
+	for _, test := range tests {
+		w := NewGameWorld(20, 20)
+		w.InitialBacteria = 0
+		w.InitialBugCount = 0
+		w.ReseedBacteria = 0
+		w.Boundary = test.boundary
+		w.Initialize()
+
+		b := forwardBug(10, 1, 100)
+		b.direction = 3 // straight up
+		w.bugs = []*Bug{b}
+		w.Next()
+
+		if alive := b.Energy > 0; alive != test.alive {
+			t.Fatalf("%s: expected the bug to be alive %v, got energy %d", test.boundary, test.alive, b.Energy)
+		}
+		if !test.alive {
+			if err := w.Next(); err != NoBugsError {
+				t.Errorf("%s: expected the absorbed bug to be removed, got %v", test.boundary, err)
+			}
+			continue
+		}
+		if b.X != test.x || b.Y != test.y || b.direction != test.direction {
+			t.Errorf("%s: expected the bug at %d, %d heading %d, got %d, %d heading %d",
+				test.boundary, test.x, test.y, test.direction, b.X, b.Y, b.direction)
+		}
+	}
+}
+
+func TestNarrowWorldsKeepBugsAlive(t *testing.T) {
+	for _, boundary := range []Boundary{TORUS_BOUNDARY, REFLECT_BOUNDARY} {
+		w := NewGameWorld(1, 20)
+		w.InitialBacteria = 0
+		w.InitialBugCount = 0
+		w.ReseedBacteria = 0
+		w.Boundary = boundary
+		w.Initialize()
+
+		w.bugs = nil
+		for direction := range w.geometry.Directions {
+			b := forwardBug(0, 10, 100)
+			b.direction = direction
+			w.bugs = append(w.bugs, b)
+		}
+		w.Next()
+
+		for _, b := range w.bugs {
+			if b.Energy <= 0 || b.X != 0 || b.Y < 0 || b.Y >= w.Height {
+				t.Errorf("%s: expected the bug alive in the world, got energy %d at %d, %d",
+					boundary, b.Energy, b.X, b.Y)
+			}
+		}
+	}
+}
+
+func TestBugsCannotJumpWalls(t *testing.T) {
+	tests := []struct {
+		name      string
+		direction int
+		wall      func(x, y int) bool
+	}{
+		{"straight", 0, func(x, y int) bool { return y == 11 }},
+		{"slanted", 1, func(x, y int) bool { return x == 11 }},
+	}
+
+	for _, test := range tests {
+		w := NewGameWorld(20, 20)
+		w.InitialBacteria = 0
+		w.InitialBugCount = 0
+		w.ReseedBacteria = 0
+		w.Terrain = NewTerrain(20, 20)
+		for y := range w.Height {
+			for x := range w.Width {
+				w.Terrain.SetWall(x, y, w.Width, w.Height, test.wall(x, y))
+			}
+		}
+		w.Initialize()
+
+		b := forwardBug(10, 10, 100)
+		b.direction = test.direction
+		w.bugs = []*Bug{b}
+		for range 3 {
+			w.Next()
+		}
+		if b.X != 10 || b.Y != 10 {
+			t.Errorf("%s: expected the bug to be held back by the wall, got %d, %d", test.name, b.X, b.Y)
+		}
+	}
+}
@@ -3,8 +3,6 @@ package world
 import (
 	"fmt"
 	"math/rand/v2"
-	"strconv"
-	"syscall/js"
 )
 
 type ScreenView int
@@ -23,98 +21,306 @@ func (n *NoBugsErrorType) Error() string {
 var NoBugsError *NoBugsErrorType = &NoBugsErrorType{}
 
 type HistoryEntry struct {
-	Cycle           int
-	BacteriaCount   int
-	BacteriaPercent float64
-	BugCount        int
-	YellowBugs      int
-	CyanBugs        int
-	MagentaBugs     int
-	RedBugs         int
+	Cycle           int            `json:"cycle"`
+	BacteriaCount   int            `json:"bacteriaCount"`
+	BacteriaPercent float64        `json:"bacteriaPercent"`
+	BugCount        int            `json:"bugCount"`
+	PredatorCount   int            `json:"predatorCount"`
+	Food            map[string]int `json:"food"`     // number of cells holding each kind of food
+	Classes         map[string]int `json:"classes"`  // number of bugs in each class
+	Mutation        string         `json:"mutation"` // the mutation policy in force
+
+	Environment map[string]int `json:"environment,omitempty"` // value of each scheduled parameter
 }
 
 type GameWorld struct {
 	Height int
 	Width  int
 
-	InitialBacteria int // percentage expressed as a whole number, i.e., 5 == 5%
-	ReseedBacteria  int
-	InitialBugCount int
-
+	InitialBacteria      int // percentage expressed as a whole number, i.e., 5 == 5%
+	ReseedBacteria       int
+	InitialBugCount      int
+	InitialPredatorCount int    // predators hunting the bugs, 0 for none
+	Seed                 uint64 // 0 picks a random seed when the world is initialized
+	KeepFullHistory      bool   // keep every history entry instead of only the last Width entries
+	RecordLineage        bool   // record the birth, death and genome of every bug
+	Rules                Rules
+	Geometry             Geometry       // headings bugs move in, applied by Initialize
+	Mutation             MutationPolicy // how children's genes differ from their parent's
+
+	// SexualReproduction makes a bug ready to reproduce wait until it meets
+	// another ready bug within Rules.MatingRadius. Each then splits into two
+	// children whose genes are a crossover of both parents.
+	SexualReproduction bool
+
+	Competition Competition // how bugs get in each other's way
+
+	FertilityRegions []*FertilityRegion
+	FertilityMap     *FertilityMap // nil reseeds uniformly
+	Growth           Growth        // how the bacteria regrow
+	Schedules        []*Schedule   // parameters changed as the cycles go by, applied in order
+
+	FoodTypes       []*FoodType // kinds of food growing besides the original bacteria
+	FoodPreferences bool        // bugs carry an evolving gene for the food they digest best
+
+	Terrain  *Terrain // walls bugs cannot cross, nil for open ground
+	Boundary Boundary // what happens to bugs at the edges of the world
+
+	// Sensing gives bugs three more genes, weighing the food they sense
+	// ahead, to their left and to their right into the chance of turning
+	// that way.
+	Sensing bool
+
+	NetworkBrains int // percentage of the initial bugs steered by a NetworkBrain instead of their turn genes
+
+	geometry      Geometry
+	classifier    Classifier
+	pcg           *rand.PCG
+	rng           *rand.Rand
 	cycle         int
 	reseedTotal   int
 	cells         []byte
 	bugs          []*Bug
+	predators     []*Bug
 	history       []HistoryEntry
-	bacteriaCount int
-
-	gameCanvas   js.Value
-	gameCtx      js.Value
-	reportCanvas js.Value
-	reportCtx    js.Value
-
-	bugsBottomLine        int
-	redBugsBottomLine     int
-	magentaBugsBottomLine int
-	cyanBugsBottomLine    int
-	yellowBugsBottomLine  int
+	geneHistogram GeneHistogram
+	bacteriaCount int // cells holding the original bacteria
+	foodCount     int // cells holding food of any kind
+	lastBugID     int
+	followedID    int
+	lineage       *Lineage
+	environment   map[string]int // value of each scheduled parameter this cycle
+
+	renderer Renderer
 }
 
 func NewGameWorld(width int, height int) *GameWorld {
 	result := &GameWorld{
-		Width:                 width,
-		Height:                height,
-		InitialBacteria:       3,
-		ReseedBacteria:        10,
-		InitialBugCount:       20,
-		reseedTotal:           0,
-		cycle:                 0,
-		bacteriaCount:         0,
-		bugsBottomLine:        height,
-		redBugsBottomLine:     height,
-		magentaBugsBottomLine: height,
-		cyanBugsBottomLine:    height,
-		yellowBugsBottomLine:  height,
-		bugs:                  []*Bug{},
-		history:               make([]HistoryEntry, 0),
+		Width:           width,
+		Height:          height,
+		InitialBacteria: 3,
+		ReseedBacteria:  10,
+		InitialBugCount: 20,
+		Rules:           DefaultRules(),
+		Geometry:        HexGeometry(),
+		Growth:          DefaultGrowth(),
+		classifier:      &ForwardClassifier{},
+		reseedTotal:     0,
+		cycle:           0,
+		bacteriaCount:   0,
+		bugs:            []*Bug{},
+		history:         make([]HistoryEntry, 0),
 	}
 	result.cells = make([]byte, width*height)
 
 	return result
 }
 
-func (w *GameWorld) Initialize(gameCanvas, gameCtx, reportCanvas, reportCtx js.Value) {
+func (w *GameWorld) Initialize() {
 	w.bacteriaCount = 0
+	w.foodCount = 0
 	w.cycle = 0
 	w.reseedTotal = 0
+	for _, r := range w.FertilityRegions {
+		r.reseedTotal = 0
+	}
+	for _, f := range w.FoodTypes {
+		f.reseedTotal = 0
+	}
 	w.bugs = []*Bug{}
+	w.predators = []*Bug{}
 	w.history = []HistoryEntry{}
-	w.gameCanvas = gameCanvas
-	w.gameCtx = gameCtx
-	w.reportCanvas = reportCanvas
-	w.reportCtx = reportCtx
+	w.lastBugID = 0
+	w.followedID = 0
+	w.lineage = nil
+	w.environment = nil
+	w.geometry = w.Geometry
+	w.classifier.Reset()
+
+	if w.Seed == 0 {
+		w.Seed = rand.Uint64()
+	}
+	w.pcg = rand.NewPCG(w.Seed, w.Seed)
+	w.rng = rand.New(w.pcg)
 
 	for i := range len(w.cells) {
-		if rand.IntN(100) < w.InitialBacteria {
-			w.cells[i] = 1
-			w.bacteriaCount++
-		} else {
-			w.cells[i] = 0
+		w.cells[i] = 0
+		if w.rng.IntN(100) < w.InitialBacteria && !w.isWall(i%w.Width, i/w.Width) {
+			w.growFood(i, 1)
 		}
 	}
+	for i, f := range w.FoodTypes {
+		w.seedFood(f, byte(i+2))
+	}
 
 	for range w.InitialBugCount {
-		x := rand.IntN(w.Width)
-		y := rand.IntN(w.Height)
-		w.bugs = append(w.bugs, NewBug(x, y))
+		x, y := w.randomOpenCell()
+		b := NewBug(w.rng, x, y, w.Rules.StartingEnergy, w.geometry.GenomeLength())
+		b.ID = w.nextBugID()
+		if w.FoodPreferences {
+			b.FoodPreference = w.rng.IntN(len(w.FoodTypes) + 1)
+		}
+		if w.NetworkBrains > 0 && w.rng.IntN(100) < w.NetworkBrains {
+			b.brain = NewNetworkBrain(w.rng, w.geometry.GenomeLength())
+		} else if w.Sensing {
+			newSenseGenes(w.rng, b)
+		}
+		w.Mutation.Clamp(b)
+		w.bugs = append(w.bugs, b)
+	}
+
+	for range w.InitialPredatorCount {
+		x, y := w.randomOpenCell()
+		p := NewBug(w.rng, x, y, w.Rules.Predator.StartingEnergy, w.geometry.GenomeLength())
+		p.ID = w.nextBugID()
+		w.Mutation.Clamp(p)
+		w.predators = append(w.predators, p)
+	}
+
+	w.classifyBugs()
+	w.geneHistogram = NewGeneHistogram(w.bugs)
+	w.updateLineage()
+}
+
+// SetClassifier changes the classification scheme and immediately
+// reclassifies every bug with it.
+func (w *GameWorld) SetClassifier(c Classifier) {
+	w.classifier = c
+	w.classifyBugs()
+}
+
+func (w *GameWorld) Classifier() Classifier {
+	return w.classifier
+}
+
+func (w *GameWorld) classifyBugs() {
+	w.classifier.Fit(w.bugs)
+	for _, b := range w.bugs {
+		b.Classification = w.classifier.Classify(b)
+	}
+}
+
+// updateLineage starts or stops recording the lineage to match
+// RecordLineage. Bugs and predators alive when recording starts become its
+// roots.
+func (w *GameWorld) updateLineage() {
+	if !w.RecordLineage {
+		w.lineage = nil
+		return
+	}
+
+	if w.lineage == nil {
+		w.lineage = NewLineage()
+		for _, b := range w.bugs {
+			w.lineage.born(b, w.cycle)
+		}
+		for _, p := range w.predators {
+			w.lineage.bornPredator(p, w.cycle)
+		}
 	}
+}
 
+// Lineage returns the recorded lineage, or nil if RecordLineage is off.
+func (w *GameWorld) Lineage() *Lineage {
+	return w.lineage
+}
+
+func (w *GameWorld) nextBugID() int {
+	w.lastBugID++
+	return w.lastBugID
 }
 
 func (w *GameWorld) HasRun() bool {
 	return w.cycle != 0
 }
 
+func (w *GameWorld) Cycle() int {
+	return w.cycle
+}
+
+// BacteriaCount returns how many cells hold the original bacteria. The
+// other food types are counted in the Food of each HistoryEntry.
+func (w *GameWorld) BacteriaCount() int {
+	return w.bacteriaCount
+}
+
+// FoodCount returns how many cells hold food of any kind.
+func (w *GameWorld) FoodCount() int {
+	return w.foodCount
+}
+
+func (w *GameWorld) Bugs() []*Bug {
+	return w.bugs
+}
+
+func (w *GameWorld) History() []HistoryEntry {
+	return w.history
+}
+
+// ActiveGeometry returns the geometry the current run was initialized with.
+func (w *GameWorld) ActiveGeometry() Geometry {
+	return w.geometry
+}
+
+// GeneHistogram returns the distribution of gene values as of the last
+// history sample.
+func (w *GameWorld) GeneHistogram() GeneHistogram {
+	return w.geneHistogram
+}
+
+// NearestBug returns the living bug closest to x, y, measuring across the
+// wrapped edges of the world, or nil if there are no bugs.
+func (w *GameWorld) NearestBug(x, y int) *Bug {
+	var result *Bug
+	best := 0
+	for _, b := range w.bugs {
+		dx, dy := w.distance(b.X, b.Y, x, y)
+		d := dx*dx + dy*dy
+		if result == nil || d < best {
+			result = b
+			best = d
+		}
+	}
+	return result
+}
+
+// Follow marks a bug and every descendant it goes on to produce as followed,
+// replacing any lineage followed before. A nil bug stops following.
+func (w *GameWorld) Follow(bug *Bug) {
+	for _, b := range w.bugs {
+		b.followed = false
+		b.trail = nil
+	}
+
+	w.followedID = 0
+	if bug != nil {
+		bug.followed = true
+		w.followedID = bug.ID
+	}
+}
+
+// FollowedID returns the ID of the bug whose lineage is followed, or 0.
+func (w *GameWorld) FollowedID() int {
+	return w.followedID
+}
+
+// IsAlive reports whether the bug is still part of the population.
+func (w *GameWorld) IsAlive(bug *Bug) bool {
+	for _, b := range w.bugs {
+		if b == bug {
+			return true
+		}
+	}
+	return false
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
 func CalculatePosition(x, y, width int) (int, error) {
 	if x >= 0 && y >= 0 {
 		return (y * width) + x, nil
@@ -145,61 +351,67 @@ func (w *GameWorld) GetCell(x, y int) (byte, error) {
 	return w.cells[pos], nil
 }
 
-func (w *GameWorld) addHistoryEntry() {
+// Summary returns a history entry for the current cycle without adding it
+// to the history. Bugs are counted by the class they were last given, since
+// classifying them again would refit the classifier between samples.
+func (w *GameWorld) Summary() HistoryEntry {
 	entry := HistoryEntry{
 		Cycle:           w.cycle,
 		BacteriaCount:   w.bacteriaCount,
 		BacteriaPercent: float64(w.bacteriaCount) / float64(w.Height*w.Width),
 		BugCount:        len(w.bugs),
-		YellowBugs:      0,
-		CyanBugs:        0,
-		MagentaBugs:     0,
-		RedBugs:         0,
+		PredatorCount:   len(w.predators),
+		Food:            w.countFood(),
+		Classes:         map[string]int{},
+		Mutation:        w.Mutation.String(),
+		Environment:     w.scheduledValues(),
 	}
 
+	for _, class := range w.classifier.Classes() {
+		entry.Classes[class.Name] = 0
+	}
 	for _, bug := range w.bugs {
-		switch bug.Classification {
-		case YELLOW:
-			entry.YellowBugs++
-		case CYAN:
-			entry.CyanBugs++
-		case MAGENTA:
-			entry.MagentaBugs++
-		default:
-			entry.RedBugs++
-		}
+		entry.Classes[bug.Classification]++
 	}
+	return entry
+}
+
+func (w *GameWorld) addHistoryEntry() {
+	w.classifyBugs()
+	entry := w.Summary()
+	w.geneHistogram = NewGeneHistogram(w.bugs)
 
 	w.history = append(w.history, entry)
-	if len(w.history) > w.Width {
+	if !w.KeepFullHistory && len(w.history) > w.Width {
 		w.history = w.history[len(w.history)-w.Width:]
 	}
 }
 
 func (w *GameWorld) Next() error {
 	w.cycle++
+	w.applySchedules()
 
-	if w.cycle%20 == 0 {
+	if w.Rules.HistoryInterval > 0 && w.cycle%w.Rules.HistoryInterval == 0 {
 		w.addHistoryEntry()
 	}
 
-	for w.reseedTotal >= 0 {
-		w.reseedTotal -= 100
-		for {
-			x := rand.IntN(w.Width)
-			y := rand.IntN(w.Height)
-			v, _ := w.GetCell(x, y)
-			if v == 0 {
-				w.SetCell(x, y, 1)
-				w.bacteriaCount++
-				break
-			}
-		}
+	if w.Growth.Model == SPREADING_GROWTH {
+		w.spread()
+	} else {
+		w.reseed()
 	}
 
-	w.reseedTotal += w.ReseedBacteria
+	for _, r := range w.FertilityRegions {
+		w.reseedRegion(r)
+	}
 
+	for i, f := range w.FoodTypes {
+		w.reseedFood(f, byte(i+2))
+	}
+
+	w.updateLineage()
 	w.updateBugs()
+	w.updatePredators()
 
 	if len(w.bugs) == 0 {
 		return NoBugsError
@@ -208,348 +420,204 @@ func (w *GameWorld) Next() error {
 	return nil
 }
 
-func (w *GameWorld) updateBugs() {
-	nextGneBugs := []*Bug{}
-
-	for _, b := range w.bugs {
-		if b.Age > 800 && b.Energy > 1000 {
-			b1 := b.NewBugFrom()
-			b1.Mutate(1)
-			nextGneBugs = append(nextGneBugs, b1)
-			b2 := b.NewBugFrom()
-			b2.Mutate(-1)
-			nextGneBugs = append(nextGneBugs, b2)
-		} else if b.Energy > 0 {
-			nextGneBugs = append(nextGneBugs, b)
+// reseed grows bacteria at random empty cells. It gives up on a bacterium
+// after as many attempts as there are cells, so a world covered in food and
+// walls does not search forever.
+func (w *GameWorld) reseed() {
+	for w.reseedTotal >= 0 {
+		w.reseedTotal -= 100
+		if w.FertilityMap != nil {
+			w.reseedFromMap()
+			continue
 		}
-	}
 
-	for _, b := range nextGneBugs {
-		b.Update(w.Width, w.Height)
-		b.Energy += w.bacteriaUnderBug(b)
-		if b.Energy > 1500 {
-			b.Energy = 1500
+		for range len(w.cells) {
+			x := w.rng.IntN(w.Width)
+			y := w.rng.IntN(w.Height)
+			if w.cells[y*w.Width+x] == 0 && !w.isWall(x, y) {
+				w.growFood(y*w.Width+x, 1)
+				break
+			}
 		}
 	}
 
-	w.bugs = nextGneBugs
+	w.reseedTotal += w.ReseedRate()
 }
 
-func (w *GameWorld) bacteriaUnderBug(bug *Bug) int {
-	result := 0
-	yd := bug.Y
-	for i := range 3 {
-		yd += i - 1
-		xd := bug.X
-		for j := range 3 {
-			xd += j - 1
-			v, _ := w.GetCell(xd, yd)
-			if v > 0 {
-				w.SetCell(xd, yd, 0)
-				w.bacteriaCount--
-				result++
-			}
+// reseedFromMap grows a bacterium at a random empty cell like reseed, but
+// only with a chance set by the fertility of the cell it lands on, so an
+// all-white map regrows like no map at all and darker areas regrow less.
+func (w *GameWorld) reseedFromMap() {
+	for range len(w.cells) {
+		x := w.rng.IntN(w.Width)
+		y := w.rng.IntN(w.Height)
+		if w.cells[y*w.Width+x] != 0 || w.isWall(x, y) {
+			continue
+		}
+		if int(w.FertilityMap.At(x, y, w.Width, w.Height)) > w.rng.IntN(255) {
+			w.growFood(y*w.Width+x, 1)
 		}
+		return
 	}
-
-	return result * 40
 }
 
-func (w *GameWorld) drawHUD(ctx js.Value) error {
-	ctx.Set("font", "20px Arial")
-	ctx.Set("fillStyle", "black")
-	ctx.Call("fillText", fmt.Sprintf("Cycle : %d", w.cycle), 30, w.Height+25)
-
-	ratio := float64(w.bacteriaCount) / float64(w.Width*w.Height) * 100
-	ctx.Call("fillText", fmt.Sprintf("Bacteria : %d (%2.1f%%)", w.bacteriaCount, ratio), 180, w.Height+25)
-
-	ctx.Call("fillText", fmt.Sprintf("Bugs : %d", len(w.bugs)), 450, w.Height+25)
-	return nil
-}
+func (w *GameWorld) updateBugs() {
+	nextGneBugs := []*Bug{}
 
-func (w *GameWorld) drawBugs(ctx js.Value) {
-	for _, b := range w.bugs {
-		b.Draw(ctx)
+	var mates map[*Bug]*Bug
+	if w.SexualReproduction {
+		mates = w.findMates()
 	}
-}
 
-func (w *GameWorld) drawGameView() error {
-	w.DrawBackground(w.gameCanvas, w.gameCtx)
-
-	w.gameCtx.Set("fillStyle", "green")
-	for x := range w.Width {
-		for y := range w.Height {
-			v, err := w.GetCell(x, y)
-			if err != nil {
-				return err
-			}
-
-			if v != 0 {
-				w.gameCtx.Call("fillRect", x, y, 1, 1)
+	for _, b := range w.bugs {
+		if w.readyToReproduce(b) && (!w.SexualReproduction || mates[b] != nil) {
+			b1 := w.newChild(b, mates[b], 1)
+			nextGneBugs = append(nextGneBugs, b1)
+			b2 := w.newChild(b, mates[b], -1)
+			nextGneBugs = append(nextGneBugs, b2)
+			if w.lineage != nil {
+				w.lineage.died(b, w.cycle)
+				w.lineage.born(b1, w.cycle)
+				w.lineage.born(b2, w.cycle)
 			}
+		} else if b.Energy > 0 {
+			nextGneBugs = append(nextGneBugs, b)
+		} else if w.lineage != nil {
+			w.lineage.died(b, w.cycle)
 		}
 	}
 
-	w.drawBugs(w.gameCtx)
-
-	w.drawHUD(w.gameCtx)
-
-	return nil
-}
-
-func (w *GameWorld) drawBugHistory() {
-	w.reportCtx.Set("strokeStyle", "lightgray")
-	w.reportCtx.Call("beginPath")
-	w.reportCtx.Call("moveTo", 0, w.bugsBottomLine)
-	w.reportCtx.Call("lineTo", w.Width, w.bugsBottomLine)
-	w.reportCtx.Call("stroke")
-
-	w.reportCtx.Set("strokeStyle", "white")
-	w.reportCtx.Call("beginPath")
-
-	startIndex := 0
-	if len(w.history) > w.Width {
-		startIndex = len(w.history) - w.Width
+	var occupied occupancy
+	if w.Competition.Exclusion {
+		occupied = w.newOccupancy(nextGneBugs)
 	}
 
-	for i := startIndex; i < len(w.history); i++ {
-		h := w.history[i]
-		x := i - startIndex
-		y := w.bugsBottomLine - h.BugCount - 2
-		if y < w.redBugsBottomLine+20 {
-			w.redBugsBottomLine = y - 30
+	for _, b := range w.feedingOrder(nextGneBugs) {
+		w.sense(b)
+		if !w.moveBug(b, occupied) {
+			continue
 		}
-		if i == 0 {
-			w.reportCtx.Call("moveTo", x, y)
-		} else {
-			w.reportCtx.Call("lineTo", x, y)
+		if b.followed {
+			b.recordTrail()
 		}
-	}
-	w.reportCtx.Call("stroke")
-}
-
-func (w *GameWorld) drawRedBugsHistory() {
-	w.reportCtx.Set("strokeStyle", "lightgray")
-	w.reportCtx.Call("beginPath")
-	w.reportCtx.Call("moveTo", 0, w.redBugsBottomLine)
-	w.reportCtx.Call("lineTo", w.Width, w.redBugsBottomLine)
-	w.reportCtx.Call("stroke")
-
-	w.reportCtx.Set("strokeStyle", "red")
-	w.reportCtx.Call("beginPath")
-
-	startIndex := 0
-	if len(w.history) > w.Width {
-		startIndex = len(w.history) - w.Width
-	}
-
-	var x int
-	for i := startIndex; i < len(w.history); i++ {
-		h := w.history[i]
-		x = i - startIndex
-		y := w.redBugsBottomLine - h.RedBugs - 2
-		if y < w.magentaBugsBottomLine+20 {
-			w.magentaBugsBottomLine = y - 30
-		}
-		if i == 0 {
-			w.reportCtx.Call("moveTo", x, y)
-		} else {
-			w.reportCtx.Call("lineTo", x, y)
+		b.Energy += w.bacteriaUnderBug(b)
+		if b.Energy > w.Rules.MaxEnergy {
+			b.Energy = w.Rules.MaxEnergy
 		}
 	}
-	w.reportCtx.Call("stroke")
+	w.contest(nextGneBugs)
 
-	w.reportCtx.Set("font", "12px Arial")
-	w.reportCtx.Set("fillStyle", "red")
-	text := strconv.Itoa(w.history[len(w.history)-1].RedBugs)
-	textMetrics := w.reportCtx.Call("measureText", text)
-	x = x - int(textMetrics.Get("width").Float()) - 5
-	if x < 1 {
-		x = 1
-	}
-	w.reportCtx.Call("fillText", text, x, w.redBugsBottomLine-5)
+	w.bugs = nextGneBugs
 }
 
-func (w *GameWorld) drawMagentaBugsHistory() {
-	w.reportCtx.Set("strokeStyle", "lightgray")
-	w.reportCtx.Call("beginPath")
-	w.reportCtx.Call("moveTo", 0, w.magentaBugsBottomLine)
-	w.reportCtx.Call("lineTo", w.Width, w.magentaBugsBottomLine)
-	w.reportCtx.Call("stroke")
-
-	w.reportCtx.Set("strokeStyle", "magenta")
-	w.reportCtx.Call("beginPath")
-
-	startIndex := 0
-	if len(w.history) > w.Width {
-		startIndex = len(w.history) - w.Width
-	}
-
-	var x int
-	for i := startIndex; i < len(w.history); i++ {
-		h := w.history[i]
-		x = i - startIndex
-		y := w.magentaBugsBottomLine - h.MagentaBugs - 2
-		if y < w.cyanBugsBottomLine+20 {
-			w.cyanBugsBottomLine = y - 30
-		}
+func (w *GameWorld) readyToReproduce(b *Bug) bool {
+	return b.Age > w.Rules.ReproduceAge && b.Energy > w.Rules.ReproduceEnergy
+}
 
-		if i == 0 {
-			w.reportCtx.Call("moveTo", x, y)
-		} else {
-			w.reportCtx.Call("lineTo", x, y)
-		}
+// newChild splits a child off parent, mixing in the genes of mate when it is
+// not nil, and mutates one gene by delta.
+func (w *GameWorld) newChild(parent, mate *Bug, delta int) *Bug {
+	child := parent.NewBugFrom()
+	child.ID = w.nextBugID()
+	if mate != nil {
+		child.Crossover(w.rng, mate)
 	}
-	w.reportCtx.Call("stroke")
-
-	w.reportCtx.Set("font", "12px Arial")
-	w.reportCtx.Set("fillStyle", "magenta")
-	text := strconv.Itoa(w.history[len(w.history)-1].MagentaBugs)
-	textMetrics := w.reportCtx.Call("measureText", text)
-	x = x - int(textMetrics.Get("width").Float()) - 5
-	if x < 1 {
-		x = 1
+	w.Mutation.Apply(w.rng, child, delta)
+	if w.FoodPreferences {
+		w.mutateFoodPreference(child)
 	}
-	w.reportCtx.Call("fillText", text, x, w.magentaBugsBottomLine-5)
-
+	child.Classification = w.classifier.Classify(child)
+	return child
 }
 
-func (w *GameWorld) drawCyanBugsHistory() {
-	w.reportCtx.Set("strokeStyle", "lightgray")
-	w.reportCtx.Call("beginPath")
-	w.reportCtx.Call("moveTo", 0, w.cyanBugsBottomLine)
-	w.reportCtx.Call("lineTo", w.Width, w.cyanBugsBottomLine)
-	w.reportCtx.Call("stroke")
-
-	w.reportCtx.Set("strokeStyle", "cyan")
-	w.reportCtx.Call("beginPath")
-
-	startIndex := 0
-	if len(w.history) > w.Width {
-		startIndex = len(w.history) - w.Width
+// findMates pairs up the bugs ready to reproduce that are within
+// Rules.MatingRadius of each other, each bug mapping to its mate. Bugs are
+// paired in order, each with the first unpaired bug close enough that has
+// the same kind of brain.
+func (w *GameWorld) findMates() map[*Bug]*Bug {
+	ready := []*Bug{}
+	for _, b := range w.bugs {
+		if w.readyToReproduce(b) {
+			ready = append(ready, b)
+		}
 	}
 
-	var x int
-	for i := startIndex; i < len(w.history); i++ {
-		h := w.history[i]
-		x = i - startIndex
-		y := w.cyanBugsBottomLine - h.CyanBugs - 2
-		if y < w.yellowBugsBottomLine+20 {
-			w.yellowBugsBottomLine = y - 30
+	mates := map[*Bug]*Bug{}
+	for i, b1 := range ready {
+		if mates[b1] != nil {
+			continue
 		}
-		if i == 0 {
-			w.reportCtx.Call("moveTo", x, y)
-		} else {
-			w.reportCtx.Call("lineTo", x, y)
+		for _, b2 := range ready[i+1:] {
+			if mates[b2] != nil || b1.Brain().Name() != b2.Brain().Name() {
+				continue
+			}
+			dx, dy := w.distance(b1.X, b1.Y, b2.X, b2.Y)
+			if dx <= w.Rules.MatingRadius && dy <= w.Rules.MatingRadius {
+				mates[b1] = b2
+				mates[b2] = b1
+				break
+			}
 		}
 	}
-	w.reportCtx.Call("stroke")
 
-	w.reportCtx.Set("font", "12px Arial")
-	w.reportCtx.Set("fillStyle", "cyan")
-	text := strconv.Itoa(w.history[len(w.history)-1].CyanBugs)
-	textMetrics := w.reportCtx.Call("measureText", text)
-	x = x - int(textMetrics.Get("width").Float()) - 5
-	if x < 1 {
-		x = 1
-	}
-	w.reportCtx.Call("fillText", text, x, w.cyanBugsBottomLine-5)
+	return mates
 }
 
-func (w *GameWorld) drawYellowBugsHistory() {
-	w.reportCtx.Set("strokeStyle", "lightgray")
-	w.reportCtx.Call("beginPath")
-	w.reportCtx.Call("moveTo", 0, w.yellowBugsBottomLine)
-	w.reportCtx.Call("lineTo", w.Width, w.yellowBugsBottomLine)
-	w.reportCtx.Call("stroke")
-
-	w.reportCtx.Set("strokeStyle", "yellow")
-	w.reportCtx.Call("beginPath")
-
-	startIndex := 0
-	if len(w.history) > w.Width {
-		startIndex = len(w.history) - w.Width
+// distance returns how far apart two points are along each axis, taking the
+// shorter way around the edges of a torus.
+func (w *GameWorld) distance(x1, y1, x2, y2 int) (int, int) {
+	dx := abs(x1 - x2)
+	dy := abs(y1 - y2)
+	if w.Boundary != TORUS_BOUNDARY {
+		return dx, dy
 	}
+	return min(dx, w.Width-dx), min(dy, w.Height-dy)
+}
 
-	var x int
-	for i := startIndex; i < len(w.history); i++ {
-		h := w.history[i]
-		x = i - startIndex
-		y := w.yellowBugsBottomLine - h.YellowBugs - 2
-		if i == 0 {
-			w.reportCtx.Call("moveTo", x, y)
-		} else {
-			w.reportCtx.Call("lineTo", x, y)
+// bacteriaUnderBug eats the food within Rules.FeedingRadius of a bug and
+// returns the energy it gives.
+func (w *GameWorld) bacteriaUnderBug(bug *Bug) int {
+	result := 0
+	for _, p := range w.Neighbors(bug.X, bug.Y, w.Rules.FeedingRadius) {
+		if v := w.clearFood(p.Y*w.Width + p.X); v > 0 {
+			result += w.foodEnergy(bug, v)
 		}
 	}
-	w.reportCtx.Call("stroke")
 
-	w.reportCtx.Set("font", "12px Arial")
-	w.reportCtx.Set("fillStyle", "yellow")
-	text := strconv.Itoa(w.history[len(w.history)-1].YellowBugs)
-	textMetrics := w.reportCtx.Call("measureText", text)
-	x = x - int(textMetrics.Get("width").Float()) - 5
-	if x < 1 {
-		x = 1
-	}
-	w.reportCtx.Call("fillText", text, x, w.yellowBugsBottomLine-5)
+	return result
 }
 
-func (w *GameWorld) drawBacteriaHistory() {
-	w.reportCtx.Set("strokeStyle", "green")
-	w.reportCtx.Call("beginPath")
+func (w *GameWorld) SetRenderer(renderer Renderer) {
+	w.renderer = renderer
+}
 
-	startIndex := 0
-	if len(w.history) > w.Width {
-		startIndex = len(w.history) - w.Width
+func (w *GameWorld) Draw(screenView ScreenView) error {
+	if w.renderer == nil {
+		return nil
 	}
 
-	for i := startIndex; i < len(w.history); i++ {
-		x := i - startIndex
-		h := w.history[i]
-		gap := float64(w.Height) / 50
-		y := w.Height - int((h.BacteriaPercent*100)*gap)
-		if y < w.bugsBottomLine {
-			w.bugsBottomLine = y - 25
-		}
-		if i == 0 {
-			w.reportCtx.Call("moveTo", x, y)
-		} else {
-			w.reportCtx.Call("lineTo", x, y)
-		}
+	if err := w.renderer.DrawBackground(screenView, w); err != nil {
+		return err
 	}
-	w.reportCtx.Call("stroke")
-}
-
-func (w *GameWorld) drawReportView() error {
-	w.DrawBackground(w.reportCanvas, w.reportCtx)
-
-	w.drawHUD(w.reportCtx)
-
-	w.drawBugHistory()
-	w.drawBacteriaHistory()
-	w.drawRedBugsHistory()
-	w.drawMagentaBugsHistory()
-	w.drawCyanBugsHistory()
-	w.drawYellowBugsHistory()
-
-	return nil
-}
 
-func (w *GameWorld) Draw(screenView ScreenView) error {
 	if screenView == GAME_VIEW {
-		return w.drawGameView()
-	} else {
-		return w.drawReportView()
+		if err := w.renderer.DrawFertility(w); err != nil {
+			return err
+		}
+		if err := w.renderer.DrawTerrain(w); err != nil {
+			return err
+		}
+		if err := w.renderer.DrawCells(w); err != nil {
+			return err
+		}
+		if err := w.renderer.DrawBugs(w); err != nil {
+			return err
+		}
+		return w.renderer.DrawHUD(screenView, w)
 	}
-}
-
-func (w *GameWorld) DrawBackground(canvas, ctx js.Value) {
-	ctx.Call("clearRect", 0, 0, canvas.Get("width").Int(), canvas.Get("height").Int())
-
-	ctx.Set("fillStyle", "black")
-	ctx.Call("fillRect", 0, 0, w.Width, w.Height)
 
-	ctx.Set("fillStyle", "gray")
-	ctx.Call("fillRect", 0, w.Height, w.Width, 40)
+	if err := w.renderer.DrawHUD(screenView, w); err != nil {
+		return err
+	}
+	return w.renderer.DrawReport(w)
 }
@@ -1,8 +1,389 @@
 package world
 
 import (
+	"math/rand/v2"
+	"reflect"
+	"slices"
 	"testing"
 )
 
 func TestCalculateNeighbors(t *testing.T) {
+	tests := []struct {
+		name     string
+		boundary Boundary
+		x, y     int
+		radius   int
+		expected []Point
+	}{
+		{"middle", TORUS_BOUNDARY, 5, 5, 1, []Point{{4, 4}, {5, 4}, {6, 4}, {4, 5}, {5, 5}, {6, 5}, {4, 6}, {5, 6}, {6, 6}}},
+		{"radius 0", TORUS_BOUNDARY, 0, 0, 0, []Point{{0, 0}}},
+		{"top left corner", TORUS_BOUNDARY, 0, 0, 1, []Point{{9, 7}, {0, 7}, {1, 7}, {9, 0}, {0, 0}, {1, 0}, {9, 1}, {0, 1}, {1, 1}}},
+		{"bottom right corner", TORUS_BOUNDARY, 9, 7, 1, []Point{{8, 6}, {9, 6}, {0, 6}, {8, 7}, {9, 7}, {0, 7}, {8, 0}, {9, 0}, {0, 0}}},
+		{"right edge", TORUS_BOUNDARY, 9, 3, 1, []Point{{8, 2}, {9, 2}, {0, 2}, {8, 3}, {9, 3}, {0, 3}, {8, 4}, {9, 4}, {0, 4}}},
+		{"wider than the world", TORUS_BOUNDARY, 2, 2, 5, nil},
+		{"clipped corner", REFLECT_BOUNDARY, 0, 0, 1, []Point{{0, 0}, {1, 0}, {0, 1}, {1, 1}}},
+		{"clipped edge", ABSORB_BOUNDARY, 9, 3, 1, []Point{{8, 2}, {9, 2}, {8, 3}, {9, 3}, {8, 4}, {9, 4}}},
+		{"centre outside", ABSORB_BOUNDARY, 5, -1, 1, []Point{{4, 0}, {5, 0}, {6, 0}}},
+	}
+
+	for _, test := range tests {
+		w := NewGameWorld(10, 8)
+		w.Boundary = test.boundary
+		got := w.Neighbors(test.x, test.y, test.radius)
+
+		expected := test.expected
+		if expected == nil {
+			// Every cell of the world, each only once.
+			for y := range 8 {
+				for x := range 10 {
+					expected = append(expected, Point{x, y})
+				}
+			}
+		}
+		if !slices.Equal(got, expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, expected, got)
+		}
+	}
+}
+
+func TestNextRunsWithoutRenderer(t *testing.T) {
+	w := NewGameWorld(100, 100)
+	w.Initialize()
+
+	for range 100 {
+		if err := w.Next(); err != nil && err != NoBugsError {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if w.Cycle() != 100 {
+		t.Errorf("expected cycle 100, got %d", w.Cycle())
+	}
+	if len(w.History()) != 5 {
+		t.Errorf("expected 5 history entries, got %d", len(w.History()))
+	}
+}
+
+type recordingRenderer struct {
+	calls []string
+}
+
+func (r *recordingRenderer) DrawBackground(screenView ScreenView, w *GameWorld) error {
+	r.calls = append(r.calls, "background")
+	return nil
+}
+
+func (r *recordingRenderer) DrawFertility(w *GameWorld) error {
+	r.calls = append(r.calls, "fertility")
+	return nil
+}
+
+func (r *recordingRenderer) DrawTerrain(w *GameWorld) error {
+	r.calls = append(r.calls, "terrain")
+	return nil
+}
+
+func (r *recordingRenderer) DrawCells(w *GameWorld) error {
+	r.calls = append(r.calls, "cells")
+	return nil
+}
+
+func (r *recordingRenderer) DrawBugs(w *GameWorld) error {
+	r.calls = append(r.calls, "bugs")
+	return nil
+}
+
+func (r *recordingRenderer) DrawHUD(screenView ScreenView, w *GameWorld) error {
+	r.calls = append(r.calls, "hud")
+	return nil
+}
+
+func (r *recordingRenderer) DrawReport(w *GameWorld) error {
+	r.calls = append(r.calls, "report")
+	return nil
+}
+
+func TestDrawUsesRenderer(t *testing.T) {
+	w := NewGameWorld(10, 10)
+	r := &recordingRenderer{}
+	w.SetRenderer(r)
+	w.Initialize()
+
+	w.Draw(GAME_VIEW)
+	w.Draw(REPORT_VIEW)
+
+	expected := []string{"background", "fertility", "terrain", "cells", "bugs", "hud", "background", "hud", "report"}
+	if len(r.calls) != len(expected) {
+		t.Fatalf("expected calls %v, got %v", expected, r.calls)
+	}
+	for i := range expected {
+		if r.calls[i] != expected[i] {
+			t.Fatalf("expected calls %v, got %v", expected, r.calls)
+		}
+	}
+}
+
+func TestSameSeedProducesSameHistory(t *testing.T) {
+	run := func(seed uint64) []HistoryEntry {
+		w := NewGameWorld(100, 100)
+		w.Seed = seed
+		w.Initialize()
+		for range 2000 {
+			if err := w.Next(); err != nil {
+				break
+			}
+		}
+		return w.History()
+	}
+
+	first := run(42)
+	second := run(42)
+	if len(first) == 0 || len(first) != len(second) {
+		t.Fatalf("expected matching non-empty histories, got %d and %d entries", len(first), len(second))
+	}
+	for i := range first {
+		if !reflect.DeepEqual(first[i], second[i]) {
+			t.Fatalf("history differs at entry %d: %+v vs %+v", i, first[i], second[i])
+		}
+	}
+}
+
+func TestSummaryIsForTheCurrentCycle(t *testing.T) {
+	w := NewGameWorld(50, 50)
+	w.Seed = 1
+	w.Initialize()
+	for range 25 {
+		w.Next()
+	}
+
+	summary := w.Summary()
+	if summary.Cycle != 25 || summary.BugCount != len(w.Bugs()) || summary.BacteriaCount != w.BacteriaCount() {
+		t.Errorf("expected a summary of cycle 25 with %d bugs and %d bacteria, got %+v", len(w.Bugs()), w.BacteriaCount(), summary)
+	}
+	if len(w.History()) != 1 {
+		t.Errorf("expected the summary to leave the history alone, got %d entries", len(w.History()))
+	}
+}
+
+func TestBacteriaUnderBugUsesRules(t *testing.T) {
+	w := NewGameWorld(20, 20)
+	w.InitialBacteria = 100
+	w.InitialBugCount = 0
+	w.Rules.FeedingRadius = 2
+	w.Rules.EnergyPerBacterium = 7
+	w.Initialize()
+
+	energy := w.bacteriaUnderBug(&Bug{X: 10, Y: 10})
+
+	if energy != 25*7 {
+		t.Errorf("expected %d energy, got %d", 25*7, energy)
+	}
+	if w.BacteriaCount() != 400-25 {
+		t.Errorf("expected %d bacteria left, got %d", 400-25, w.BacteriaCount())
+	}
+}
+
+func TestBacteriaUnderBugWrapsAroundEdges(t *testing.T) {
+	const width, height = 12, 9
+	tests := []struct {
+		x, y   int
+		radius int
+	}{
+		{0, 0, 1},
+		{width - 1, 0, 1},
+		{0, height - 1, 1},
+		{width - 1, height - 1, 1},
+		{0, 4, 1},
+		{width - 1, 4, 2},
+		{6, 0, 2},
+		{6, height - 1, 2},
+		{6, 4, 1},
+	}
+
+	for _, test := range tests {
+		w := NewGameWorld(width, height)
+		w.InitialBacteria = 100
+		w.InitialBugCount = 0
+		w.Rules.FeedingRadius = test.radius
+		w.Rules.EnergyPerBacterium = 1
+		w.Initialize()
+
+		cells := (2*test.radius + 1) * (2*test.radius + 1)
+		if energy := w.bacteriaUnderBug(&Bug{X: test.x, Y: test.y}); energy != cells {
+			t.Errorf("bug at %d, %d: expected to eat %d cells, ate %d", test.x, test.y, cells, energy)
+		}
+		if w.BacteriaCount() != width*height-cells {
+			t.Errorf("bug at %d, %d: expected %d bacteria left, got %d", test.x, test.y, width*height-cells, w.BacteriaCount())
+		}
+
+		for y := range height {
+			for x := range width {
+				dx := min(abs(x-test.x), width-abs(x-test.x))
+				dy := min(abs(y-test.y), height-abs(y-test.y))
+				eaten := dx <= test.radius && dy <= test.radius
+				if v, _ := w.GetCell(x, y); (v == 0) != eaten {
+					t.Errorf("bug at %d, %d: expected cell %d, %d eaten %v, got value %d", test.x, test.y, x, y, eaten, v)
+				}
+			}
+		}
+	}
+}
+
+func TestNearestBugWrapsAroundEdges(t *testing.T) {
+	w := NewGameWorld(100, 100)
+	w.InitialBugCount = 0
+	w.Initialize()
+
+	middle := &Bug{X: 50, Y: 50}
+	edge := &Bug{X: 98, Y: 2}
+	w.bugs = []*Bug{middle, edge}
+
+	if b := w.NearestBug(45, 52); b != middle {
+		t.Errorf("expected the middle bug, got %+v", b)
+	}
+	if b := w.NearestBug(1, 97); b != edge {
+		t.Errorf("expected the edge bug across the wrap, got %+v", b)
+	}
+}
+
+func TestTurnProbabilities(t *testing.T) {
+	b := &Bug{geneWeight: []int{1, 0, 4, 0, 0, 0}, totalOfWeights: 5}
+	p := b.TurnProbabilities()
+	if !slices.Equal(p, []float64{0.2, 0, 0.6, 0, 0, 0.2}) {
+		t.Errorf("unexpected turn probabilities %v", p)
+	}
+
+	counts := make([]int, 6)
+	rng := rand.New(rand.NewPCG(1, 1))
+	for range 10000 {
+		counts[b.selectTurn(rng)]++
+	}
+	for i := range 6 {
+		if diff := float64(counts[i])/10000 - p[i]; diff > 0.02 || diff < -0.02 {
+			t.Errorf("turn %d picked %d times, expected probability %f", i, counts[i], p[i])
+		}
+	}
+
+	b = &Bug{geneWeight: make([]int, 6)}
+	for i, v := range b.TurnProbabilities() {
+		if v != 1.0/6 {
+			t.Errorf("expected uniform probability for turn %d, got %f", i, v)
+		}
+	}
+}
+
+func TestFollowedLineage(t *testing.T) {
+	w := NewGameWorld(100, 100)
+	w.InitialBugCount = 2
+	w.Seed = 1
+	w.Initialize()
+
+	parent := w.Bugs()[0]
+	other := w.Bugs()[1]
+	w.Follow(parent)
+	w.Next()
+	if len(parent.Trail()) != 1 {
+		t.Fatalf("expected a trail of 1 position, got %d", len(parent.Trail()))
+	}
+
+	parent.Age = w.Rules.ReproduceAge + 1
+	parent.Energy = w.Rules.ReproduceEnergy + 1
+	w.Next()
+
+	children := 0
+	for _, b := range w.Bugs() {
+		if b == other {
+			if b.Followed() {
+				t.Error("expected the unrelated bug not to be followed")
+			}
+			continue
+		}
+
+		children++
+		if b.ParentID != parent.ID || b.ID == parent.ID || b.ID == 0 {
+			t.Errorf("expected a new child of bug %d, got id %d parent %d", parent.ID, b.ID, b.ParentID)
+		}
+		if !b.Followed() {
+			t.Error("expected the child to be followed")
+		}
+		if len(b.Trail()) != 2 {
+			t.Errorf("expected the child to extend its parent's trail, got %d positions", len(b.Trail()))
+		}
+	}
+	if children != 2 {
+		t.Errorf("expected 2 children, got %d", children)
+	}
+	if w.FollowedID() != parent.ID {
+		t.Errorf("expected followed id %d, got %d", parent.ID, w.FollowedID())
+	}
+}
+
+func TestCrossover(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 1))
+	for range 20 {
+		b := bugWithGenes(1, 1, 1, 1, 1, 1)
+		mate := bugWithGenes(2, 2, 2, 2, 2, 2)
+		mate.ID = 7
+		b.Crossover(rng, mate)
+
+		cut := slices.Index(b.geneValue, 2)
+		if cut < 1 || slices.ContainsFunc(b.geneValue[cut:], func(v int) bool { return v != 2 }) {
+			t.Fatalf("expected genes of b then genes of mate, got %v", b.geneValue)
+		}
+		if b.MateID != 7 || b.totalOfWeights != cut+4*(6-cut) {
+			t.Errorf("expected mate 7 and updated weights, got mate %d total %d", b.MateID, b.totalOfWeights)
+		}
+	}
+}
+
+func TestCrossoverInheritsFoodPreference(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 1))
+	fromMate := 0
+	for range 100 {
+		b := bugWithGenes(1, 1, 1, 1, 1, 1)
+		mate := bugWithGenes(2, 2, 2, 2, 2, 2)
+		mate.FoodPreference = 1
+		b.Crossover(rng, mate)
+		if b.FoodPreference == 1 {
+			fromMate++
+		}
+	}
+	if fromMate == 0 || fromMate == 100 {
+		t.Errorf("expected the preference to come from either bug, got the mate's %d times in 100", fromMate)
+	}
+}
+
+func TestSexualReproductionNeedsMate(t *testing.T) {
+	w := NewGameWorld(100, 100)
+	w.InitialBugCount = 0
+	w.SexualReproduction = true
+	w.Initialize()
+
+	ready := func(x, y int, gene int) *Bug {
+		b := bugWithGenes(gene, gene, gene, gene, gene, gene)
+		b.ID = w.nextBugID()
+		b.X, b.Y = x, y
+		b.Age = w.Rules.ReproduceAge + 1
+		b.Energy = w.Rules.ReproduceEnergy + 1
+		return b
+	}
+
+	w.bugs = []*Bug{ready(10, 10, 1), ready(50, 50, 1)}
+	w.Next()
+	if len(w.Bugs()) != 2 {
+		t.Fatalf("expected bugs without a mate to wait, got %d bugs", len(w.Bugs()))
+	}
+
+	b1 := ready(99, 99, 1)
+	b2 := ready(1, 0, 3)
+	w.bugs = []*Bug{b1, b2}
+	w.Next()
+	if len(w.Bugs()) != 4 {
+		t.Fatalf("expected two children from each parent, got %d bugs", len(w.Bugs()))
+	}
+	for _, b := range w.Bugs() {
+		if (b.ParentID != b1.ID || b.MateID != b2.ID) && (b.ParentID != b2.ID || b.MateID != b1.ID) {
+			t.Errorf("expected a child of bugs %d and %d, got parent %d mate %d", b1.ID, b2.ID, b.ParentID, b.MateID)
+		}
+	}
 }